@@ -4,6 +4,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -11,18 +12,29 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 )
 
 const applyAction = "apply"
 
+var (
+	// ErrPlanFileVersion defines the error type when the plan
+	// file was created by a different version of terraform.
+	ErrPlanFileVersion = errors.New("plan file was created by a different terraform version")
+)
+
 // Apply represents the plugin configuration for apply information.
 type Apply struct {
 	// skip interactive approval of plan before applying. i.e. "-auto-approve"
 	AutoApprove bool
 	// path to backup the existing state file before modifying. i.e. "-backup=path "
 	Backup string
+	// safeguards for a saved plan file which only destroys resources
+	Destroy *Destroy
 	// terraform file or directory to apply
 	Directory string
+	// thresholds the saved plan file must not exceed
+	Guardrails *Guardrails
 	// the state file when locking is supported. i.e. -lock=true
 	Lock bool
 	// duration to retry a state lock. i.e. "-lock-timeout=0s"
@@ -33,14 +45,18 @@ type Apply struct {
 	NoColor bool
 	// limit the number of parallel resource operations. i.e. "-parallelism=n"
 	Parallelism int
+	// saved plan file, relative to the directory, to apply. i.e. "terraform apply plan.out"
+	PlanFile string
 	// update state prior to checking for differences. i.e. "-refresh=true"
 	Refresh bool
+	// resources to replace, requires terraform 0.15.2 or later. i.e. "-replace=resource"
+	Replace []string
 	// path to read and save state (unless state-out is specified). i.e. "-state=path"
 	State string
 	// path to write state to that is different than state. i.e. "-state-out=path"
 	StateOut string
-	// resource to target. i.e. "-target=resource"
-	Target string
+	// resources to target. i.e. "-target=resource"
+	Targets []string
 	// set a variable in the Terraform configuration. i.e. "-var 'foo=bar'"
 	Vars []string
 	// set variables in the Terraform configuration from a file. i.e. "-var-file=foo"
@@ -106,8 +122,8 @@ func (a *Apply) Command(ctx context.Context) *exec.Cmd {
 		flags = append(flags, fmt.Sprintf("-parallelism=%d", a.Parallelism))
 	}
 
-	// check if Refresh is provided
-	if a.Refresh {
+	// check if Refresh is provided and not applying a saved plan
+	if a.Refresh && len(a.PlanFile) == 0 {
 		// add flag for Refresh from provided apply command
 		flags = append(flags, "-refresh=true")
 	}
@@ -124,48 +140,85 @@ func (a *Apply) Command(ctx context.Context) *exec.Cmd {
 		flags = append(flags, fmt.Sprintf("-state-out=%s", a.StateOut))
 	}
 
-	// check if Target is provided
-	if len(a.Target) > 0 {
-		// add flag for Target from provided apply command
-		flags = append(flags, fmt.Sprintf("-target=%s", a.Target))
+	// check if Replace is provided and not applying a saved plan
+	if len(a.Replace) > 0 && len(a.PlanFile) == 0 {
+		for _, r := range a.Replace {
+			// add flag for Replace from provided apply command
+			flags = append(flags, fmt.Sprintf("-replace=%s", r))
+		}
+	}
+
+	// check if Targets is provided and not applying a saved plan
+	if len(a.Targets) > 0 && len(a.PlanFile) == 0 {
+		for _, t := range a.Targets {
+			// add flag for Targets from provided apply command
+			flags = append(flags, fmt.Sprintf("-target=%s", t))
+		}
 	}
 
-	// check if VarFiles is provided
-	if len(a.VarFiles) > 0 {
+	// check if VarFiles is provided and not applying a saved plan
+	if len(a.VarFiles) > 0 && len(a.PlanFile) == 0 {
 		for _, v := range a.VarFiles {
 			// add flag for VarFiles from provided command
 			flags = append(flags, fmt.Sprintf(`-var-file=%s`, v))
 		}
 	}
 
-	// check if Vars is provided
-	if len(a.Vars) > 0 {
+	// check if Vars is provided and not applying a saved plan
+	if len(a.Vars) > 0 && len(a.PlanFile) == 0 {
 		for _, v := range a.Vars {
 			// add flag for Vars from provided command
 			flags = append(flags, fmt.Sprintf(`-var=%s`, v))
 		}
 	}
 
-	// check if Directory is provided and terraform version doesn't support chdir
-	if a.Directory != "." && !SupportsChdir(a.Version) {
+	// check if PlanFile is provided
+	if len(a.PlanFile) > 0 {
+		// add the saved plan file in place of the directory
+		flags = append(flags, a.planPath())
+	} else if a.Directory != "." && !SupportsChdir(a.Version) {
+		// add the directory since terraform version doesn't support chdir
 		flags = append(flags, a.Directory)
 	}
 
 	globalFlags = append(globalFlags, applyAction)
 
 	//nolint:gosec // ignore G204
-	return exec.CommandContext(ctx, _terraform, append(globalFlags, flags...)...)
+	return exec.CommandContext(ctx, terraformBin, append(globalFlags, flags...)...)
 }
 
 // Exec formats and runs the commands for applying Terraform.
 func (a *Apply) Exec(ctx context.Context) error {
 	logrus.Trace("running apply with provided configuration")
 
+	// check if PlanFile is provided
+	if len(a.PlanFile) > 0 {
+		// verify the saved plan file can be applied
+		plan, err := a.VerifyPlan(ctx)
+		if err != nil {
+			return err
+		}
+
+		// verify the saved plan file doesn't exceed the guardrails
+		err = a.Guardrails.Check(plan)
+		if err != nil {
+			return err
+		}
+
+		// verify a saved plan which only destroys resources passes the destroy safeguards
+		if plan.DestroyOnly() {
+			err = a.Destroy.Planned()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	// create the apply command for the file
 	cmd := a.Command(ctx)
 
 	// run the apply command for the file
-	err := execCmd(cmd)
+	err := execCmd(ctx, cmd)
 	if err != nil {
 		return err
 	}
@@ -173,6 +226,57 @@ func (a *Apply) Exec(ctx context.Context) error {
 	return nil
 }
 
+// VerifyPlan verifies the saved plan file exists and
+// was created by the same version of terraform.
+func (a *Apply) VerifyPlan(ctx context.Context) (*planJSON, error) {
+	logrus.Tracef("verifying plan file %s", a.PlanFile)
+
+	// use custom filesystem which enables us to test
+	fs := &afero.Afero{
+		Fs: appFS,
+	}
+
+	// check if the plan file was created by a previous step
+	ok, err := fs.Exists(localPath(a.Directory, a.PlanFile))
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("plan file %s does not exist in %s", a.PlanFile, a.Directory)
+	}
+
+	plan, err := readPlan(ctx, a.Directory, a.Version, a.PlanFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// check if the plan version matches the running version
+	if a.Version != nil {
+		v, err := semver.NewVersion(plan.TerraformVersion)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse plan file terraform version: %w", err)
+		}
+
+		if !v.Equal(a.Version) {
+			return nil, fmt.Errorf("%w: %s (running %s)", ErrPlanFileVersion, v, a.Version)
+		}
+	}
+
+	return plan, nil
+}
+
+// planPath returns the path to the saved plan file
+// as an argument for the apply command.
+func (a *Apply) planPath() string {
+	// check if Directory is provided and terraform version doesn't support chdir
+	if a.Directory != "." && !SupportsChdir(a.Version) {
+		return localPath(a.Directory, a.PlanFile)
+	}
+
+	return a.PlanFile
+}
+
 // Validate verifies the Delete is properly configured.
 func (a *Apply) Validate() error {
 	logrus.Trace("validating plan plugin configuration")
@@ -181,5 +285,25 @@ func (a *Apply) Validate() error {
 		logrus.Warn("terraform apply will run in current dir")
 	}
 
+	// verify the plan file won't be confused with the state file
+	if samePath(a.PlanFile, a.State) || samePath(a.PlanFile, a.StateOut) {
+		return fmt.Errorf("%w: %s", ErrPlanStateConflict, a.PlanFile)
+	}
+
+	// verify the guardrails have a saved plan file to check
+	if a.Guardrails.Enabled() && len(a.PlanFile) == 0 {
+		return fmt.Errorf("%w: plan_file must be provided to apply with guardrails", ErrGuardrail)
+	}
+
+	// verify the version supports replace
+	if len(a.Replace) > 0 && !SupportsReplace(a.Version) {
+		return unsupported(_replace, a.Version)
+	}
+
+	// check if planning flags are provided with a saved plan
+	if len(a.PlanFile) > 0 && (a.Refresh || len(a.Replace) > 0 || len(a.Targets) > 0 || len(a.Vars) > 0 || len(a.VarFiles) > 0) {
+		logrus.Warnf("refresh, replace, targets, vars and var_files are ignored when applying plan file %s", a.PlanFile)
+	}
+
 	return nil
 }
@@ -3,13 +3,18 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"slices"
 	"testing"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/spf13/afero"
 )
 
 func TestTerraform_Apply_Command(t *testing.T) {
@@ -29,9 +34,10 @@ func TestTerraform_Apply_Command(t *testing.T) {
 		NoColor:     true,
 		Parallelism: 1,
 		Refresh:     true,
+		Replace:     []string{"aws_instance.web"},
 		State:       "state.tf",
 		StateOut:    "stateout.tf",
-		Target:      "target.tf",
+		Targets:     []string{"target1.tf", "target2.tf"},
 		Vars:        []string{"foo=bar", "bar=foo"},
 		VarFiles:    []string{"vars1.tf", "vars2.tf"},
 		Version:     v,
@@ -40,7 +46,7 @@ func TestTerraform_Apply_Command(t *testing.T) {
 	//nolint:gosec // ignore G204
 	want := exec.CommandContext(
 		t.Context(),
-		_terraform,
+		terraformBin,
 		fmt.Sprintf("-chdir=%s", a.Directory),
 		applyAction,
 		"-auto-approve",
@@ -53,7 +59,9 @@ func TestTerraform_Apply_Command(t *testing.T) {
 		"-refresh=true",
 		fmt.Sprintf("-state=%s", a.State),
 		fmt.Sprintf("-state-out=%s", a.StateOut),
-		fmt.Sprintf("-target=%s", a.Target),
+		fmt.Sprintf("-replace=%s", a.Replace[0]),
+		fmt.Sprintf("-target=%s", a.Targets[0]),
+		fmt.Sprintf("-target=%s", a.Targets[1]),
 		fmt.Sprintf("-var-file=%s", a.VarFiles[0]),
 		fmt.Sprintf("-var-file=%s", a.VarFiles[1]),
 		fmt.Sprintf("-var=%s", a.Vars[0]),
@@ -89,7 +97,7 @@ func TestTerraform_Apply_Command_tf13(t *testing.T) {
 		Refresh:     true,
 		State:       "state.tf",
 		StateOut:    "stateout.tf",
-		Target:      "target.tf",
+		Targets:     []string{"target1.tf", "target2.tf"},
 		Vars:        []string{"foo=bar", "bar=foo"},
 		VarFiles:    []string{"vars1.tf", "vars2.tf"},
 		Version:     v,
@@ -98,7 +106,7 @@ func TestTerraform_Apply_Command_tf13(t *testing.T) {
 	//nolint:gosec // ignore G204
 	want := exec.CommandContext(
 		t.Context(),
-		_terraform,
+		terraformBin,
 		applyAction,
 		"-auto-approve",
 		fmt.Sprintf("-backup=%s", a.Backup),
@@ -110,7 +118,8 @@ func TestTerraform_Apply_Command_tf13(t *testing.T) {
 		"-refresh=true",
 		fmt.Sprintf("-state=%s", a.State),
 		fmt.Sprintf("-state-out=%s", a.StateOut),
-		fmt.Sprintf("-target=%s", a.Target),
+		fmt.Sprintf("-target=%s", a.Targets[0]),
+		fmt.Sprintf("-target=%s", a.Targets[1]),
 		fmt.Sprintf("-var-file=%s", a.VarFiles[0]),
 		fmt.Sprintf("-var-file=%s", a.VarFiles[1]),
 		fmt.Sprintf("-var=%s", a.Vars[0]),
@@ -128,6 +137,97 @@ func TestTerraform_Apply_Command_tf13(t *testing.T) {
 	}
 }
 
+func TestTerraform_Apply_Command_PlanFile(t *testing.T) {
+	// setup types
+	v, err := semver.NewVersion("1.0.0")
+	if err != nil {
+		t.Error(err)
+	}
+
+	a := &Apply{
+		AutoApprove: true,
+		Directory:   "foobar/",
+		Lock:        true,
+		PlanFile:    "plan.out",
+		Refresh:     true,
+		Targets:     []string{"target1.tf", "target2.tf"},
+		Vars:        []string{"foo=bar"},
+		VarFiles:    []string{"vars1.tf"},
+		Version:     v,
+	}
+
+	//nolint:gosec // ignore G204
+	want := exec.CommandContext(
+		t.Context(),
+		terraformBin,
+		fmt.Sprintf("-chdir=%s", a.Directory),
+		applyAction,
+		"-auto-approve",
+		"-lock=true",
+		a.PlanFile,
+	)
+
+	got := a.Command(t.Context())
+	if got.Path != want.Path {
+		t.Errorf("Command path is %v, want %v", got.Path, want.Path)
+	}
+
+	if !slices.Equal(got.Args, want.Args) {
+		t.Errorf("Command args is %v, want %v", got.Args, want.Args)
+	}
+}
+
+func TestTerraform_Apply_Command_PlanFile_tf13(t *testing.T) {
+	// setup types
+	v, err := semver.NewVersion("0.13.0")
+	if err != nil {
+		t.Error(err)
+	}
+
+	a := &Apply{
+		AutoApprove: true,
+		Directory:   "foobar/",
+		PlanFile:    "plan.out",
+		Version:     v,
+	}
+
+	//nolint:gosec // ignore G204
+	want := exec.CommandContext(
+		t.Context(),
+		terraformBin,
+		applyAction,
+		"-auto-approve",
+		"foobar/plan.out",
+	)
+
+	got := a.Command(t.Context())
+	if got.Path != want.Path {
+		t.Errorf("Command path is %v, want %v", got.Path, want.Path)
+	}
+
+	if !slices.Equal(got.Args, want.Args) {
+		t.Errorf("Command args is %v, want %v", got.Args, want.Args)
+	}
+}
+
+func TestTerraform_Apply_VerifyPlan_NoFile(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	v, _ := semver.NewVersion("1.0.0")
+	// setup types
+	a := &Apply{
+		Directory: "foobar/",
+		PlanFile:  "plan.out",
+		Version:   v,
+	}
+
+	_, err := a.VerifyPlan(t.Context())
+	if err == nil {
+		t.Errorf("VerifyPlan should have returned err")
+	}
+}
+
 func TestTerraform_Apply_Exec_Error(t *testing.T) {
 	v, _ := semver.NewVersion("1.0.0")
 	// setup types
@@ -142,6 +242,64 @@ func TestTerraform_Apply_Exec_Error(t *testing.T) {
 	}
 }
 
+func TestTerraform_Apply_Exec_DestroyPlan(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewOsFs()
+
+	// setup terraform which shows a saved plan that only destroys resources
+	dir := t.TempDir()
+
+	script := `#!/bin/sh
+case "$*" in
+  *show*) echo '{"resource_changes": [{"address": "null_resource.foo", "change": {"actions": ["delete"]}}]}' ;;
+  *apply*) touch "$(dirname "$0")/applied" ;;
+esac
+`
+
+	err := os.WriteFile(filepath.Join(dir, "terraform"), []byte(script), 0755)
+	if err != nil {
+		t.Errorf("unable to create file: %v", err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "plan.out"), nil, 0644)
+	if err != nil {
+		t.Errorf("unable to create file: %v", err)
+	}
+
+	terraformBin = filepath.Join(dir, "terraform")
+	t.Cleanup(func() { terraformBin = _terraform })
+
+	// setup types
+	a := &Apply{
+		Destroy:   &Destroy{Directory: dir},
+		Directory: dir,
+		PlanFile:  "plan.out",
+	}
+
+	err = a.Exec(withOutput(t.Context(), new(bytes.Buffer)))
+	if !errors.Is(err, ErrDestroyNotConfirmed) {
+		t.Errorf("Exec returned err %v, want %v", err, ErrDestroyNotConfirmed)
+	}
+
+	ok, _ := afero.Exists(appFS, filepath.Join(dir, "applied"))
+	if ok {
+		t.Errorf("Exec should not have applied the plan")
+	}
+
+	// verify the plan is applied once destroy is confirmed
+	a.Destroy.Confirm = []string{dir}
+
+	err = a.Exec(withOutput(t.Context(), new(bytes.Buffer)))
+	if err != nil {
+		t.Errorf("Exec returned err: %v", err)
+	}
+
+	ok, _ = afero.Exists(appFS, filepath.Join(dir, "applied"))
+	if !ok {
+		t.Errorf("Exec should have applied the plan")
+	}
+}
+
 func TestTerraform_Apply_Validate(t *testing.T) {
 	v, _ := semver.NewVersion("1.0.0")
 	// setup types
@@ -167,3 +325,63 @@ func TestTerraform_Apply_Validate(t *testing.T) {
 		}
 	}
 }
+
+func TestTerraform_Apply_Validate_StateConflict(t *testing.T) {
+	v, _ := semver.NewVersion("1.0.0")
+	// setup types
+	tests := []struct {
+		apply *Apply
+	}{
+		{
+			apply: &Apply{Directory: "foobar/", PlanFile: "terraform.tfstate", State: "terraform.tfstate", Version: v},
+		},
+		{
+			apply: &Apply{Directory: "foobar/", PlanFile: "out.tfstate", StateOut: "out.tfstate", Version: v},
+		},
+	}
+
+	// run test
+	for _, test := range tests {
+		err := test.apply.Validate()
+		if !errors.Is(err, ErrPlanStateConflict) {
+			t.Errorf("Validate returned err %v, want %v", err, ErrPlanStateConflict)
+		}
+	}
+}
+
+func TestTerraform_Apply_Validate_Guardrails(t *testing.T) {
+	v, _ := semver.NewVersion("1.0.0")
+	// setup types
+	a := &Apply{
+		Directory:  "foobar/",
+		Guardrails: &Guardrails{MaxDestroy: 0, MaxChanges: -1},
+		Version:    v,
+	}
+
+	err := a.Validate()
+	if !errors.Is(err, ErrGuardrail) {
+		t.Errorf("Validate returned err %v, want %v", err, ErrGuardrail)
+	}
+
+	a.PlanFile = "plan.out"
+
+	err = a.Validate()
+	if err != nil {
+		t.Errorf("Validate returned err: %v", err)
+	}
+}
+
+func TestTerraform_Apply_Validate_Replace(t *testing.T) {
+	v, _ := semver.NewVersion("0.14.0")
+	// setup types
+	a := &Apply{
+		Directory: "foobar/",
+		Replace:   []string{"aws_instance.web"},
+		Version:   v,
+	}
+
+	err := a.Validate()
+	if !errors.Is(err, ErrUnsupportedFlag) {
+		t.Errorf("Validate returned err %v, want %v", err, ErrUnsupportedFlag)
+	}
+}
@@ -0,0 +1,240 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// ErrInvalidArchive defines the error type when an
+// archive contains an entry outside of its destination.
+var ErrInvalidArchive = errors.New("invalid archive entry")
+
+// archive is a helper function to create a gzip compressed
+// tarball at path containing the provided names within dir.
+func archive(path, dir string, names ...string) error {
+	logrus.Tracef("creating archive %s from %s", path, dir)
+
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	// send Filesystem call to create directory path for archive
+	err := a.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	f, err := a.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	for _, name := range names {
+		root := filepath.Join(dir, name)
+
+		// skip any files that don't exist in the directory
+		_, err = a.Stat(root)
+		if errors.Is(err, fs.ErrNotExist) {
+			logrus.Debugf("skipping %s for archive: file does not exist", root)
+
+			continue
+		}
+
+		err = a.Walk(root, func(p string, info fs.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			return addToArchive(a, tw, dir, p, info)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+// addToArchive is a helper function to write a single
+// file, directory or symlink into the provided tarball.
+func addToArchive(a *afero.Afero, tw *tar.Writer, dir, p string, info fs.FileInfo) error {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil {
+		return err
+	}
+
+	// capture the link target when the filesystem supports symlinks
+	var link string
+
+	if info.Mode()&fs.ModeSymlink != 0 {
+		reader, ok := a.Fs.(afero.LinkReader)
+		if !ok {
+			return fmt.Errorf("unable to read symlink %s", p)
+		}
+
+		link, err = reader.ReadlinkIfPossible(p)
+		if err != nil {
+			return err
+		}
+	}
+
+	hdr, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+
+	hdr.Name = filepath.ToSlash(rel)
+
+	err = tw.WriteHeader(hdr)
+	if err != nil {
+		return err
+	}
+
+	// only regular files have content to copy
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	f, err := a.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(tw, f)
+
+	return err
+}
+
+// extract is a helper function to unpack a gzip
+// compressed tarball at path into dir.
+func extract(path, dir string) error {
+	logrus.Tracef("extracting archive %s to %s", path, dir)
+
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	f, err := a.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+
+	// variable to store the symlinks extracted from the archive
+	links := make(map[string]bool)
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// verify the entry does not escape the destination
+		name := filepath.FromSlash(hdr.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("%w: %s", ErrInvalidArchive, hdr.Name)
+		}
+
+		// verify the entry is not written through an extracted symlink
+		if throughLink(links, filepath.Clean(name)) {
+			return fmt.Errorf("%w: %s is within a symlink", ErrInvalidArchive, hdr.Name)
+		}
+
+		err = extractEntry(a, tr, hdr, filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+
+		if hdr.Typeflag == tar.TypeSymlink {
+			links[filepath.Clean(name)] = true
+		}
+	}
+}
+
+// throughLink is a helper function to check if the path
+// or any of its parents is one of the provided symlinks.
+func throughLink(links map[string]bool, path string) bool {
+	for p := path; p != "." && p != string(filepath.Separator); p = filepath.Dir(p) {
+		if links[p] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// extractEntry is a helper function to write a single
+// tarball entry to the provided target path.
+func extractEntry(a *afero.Afero, tr *tar.Reader, hdr *tar.Header, target string) error {
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return a.MkdirAll(target, hdr.FileInfo().Mode().Perm())
+	case tar.TypeSymlink:
+		linker, ok := a.Fs.(afero.Linker)
+		if !ok {
+			return fmt.Errorf("unable to create symlink %s", target)
+		}
+
+		err := a.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return err
+		}
+
+		return linker.SymlinkIfPossible(hdr.Linkname, target)
+	case tar.TypeReg:
+		err := a.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return err
+		}
+
+		f, err := a.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, hdr.FileInfo().Mode().Perm())
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		//nolint:gosec // ignore G110 since archives are created by the plugin
+		_, err = io.Copy(f, tr)
+
+		return err
+	default:
+		logrus.Debugf("skipping unsupported archive entry %s", hdr.Name)
+
+		return nil
+	}
+}
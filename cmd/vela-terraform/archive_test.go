@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestTerraform_archive(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	err := a.WriteFile("foobar/.terraform/modules/modules.json", []byte("{}"), 0644)
+	if err != nil {
+		t.Errorf("Unable to write file: %v", err)
+	}
+
+	err = a.WriteFile("foobar/.terraform.lock.hcl", []byte("# lock"), 0644)
+	if err != nil {
+		t.Errorf("Unable to write file: %v", err)
+	}
+
+	// run test
+	err = archive("init.tar.gz", "foobar", _dataDir, _lockFile, "missing")
+	if err != nil {
+		t.Errorf("archive returned err: %v", err)
+	}
+
+	err = extract("init.tar.gz", "restored")
+	if err != nil {
+		t.Errorf("extract returned err: %v", err)
+	}
+
+	got, err := a.ReadFile("restored/.terraform/modules/modules.json")
+	if err != nil {
+		t.Errorf("Unable to read file: %v", err)
+	}
+
+	if string(got) != "{}" {
+		t.Errorf("extract file is %s, want %s", got, "{}")
+	}
+
+	got, err = a.ReadFile("restored/.terraform.lock.hcl")
+	if err != nil {
+		t.Errorf("Unable to read file: %v", err)
+	}
+
+	if string(got) != "# lock" {
+		t.Errorf("extract file is %s, want %s", got, "# lock")
+	}
+}
+
+func TestTerraform_extract_SymlinkEntry(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name  string
+		entry string
+	}{
+		{
+			name:  "within symlink",
+			entry: ".terraform/x/terraform",
+		},
+		{
+			name:  "symlink",
+			entry: ".terraform/x",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// setup filesystem
+			appFS = afero.NewOsFs()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "evil.tar.gz")
+
+			f, err := appFS.Create(path)
+			if err != nil {
+				t.Errorf("Unable to create file: %v", err)
+			}
+
+			gw := gzip.NewWriter(f)
+			tw := tar.NewWriter(gw)
+
+			outside := t.TempDir()
+
+			headers := []*tar.Header{
+				{Name: ".terraform/x", Typeflag: tar.TypeSymlink, Linkname: outside},
+				{Name: test.entry, Typeflag: tar.TypeReg, Mode: 0644},
+			}
+
+			for _, hdr := range headers {
+				err = tw.WriteHeader(hdr)
+				if err != nil {
+					t.Errorf("Unable to write header: %v", err)
+				}
+			}
+
+			tw.Close()
+			gw.Close()
+			f.Close()
+
+			// run test
+			err = extract(path, filepath.Join(dir, "foobar"))
+			if !errors.Is(err, ErrInvalidArchive) {
+				t.Errorf("extract returned err %v, want %v", err, ErrInvalidArchive)
+			}
+
+			entries, _ := os.ReadDir(outside)
+			if len(entries) > 0 {
+				t.Errorf("extract should not have written %v outside of the directory", entries)
+			}
+		})
+	}
+}
+
+func TestTerraform_extract_InvalidEntry(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	f, err := appFS.Create("evil.tar.gz")
+	if err != nil {
+		t.Errorf("Unable to create file: %v", err)
+	}
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+
+	err = tw.WriteHeader(&tar.Header{
+		Name:     "../evil",
+		Typeflag: tar.TypeReg,
+		Mode:     0644,
+	})
+	if err != nil {
+		t.Errorf("Unable to write header: %v", err)
+	}
+
+	tw.Close()
+	gw.Close()
+	f.Close()
+
+	// run test
+	err = extract("evil.tar.gz", "foobar")
+	if !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("extract returned err %v, want %v", err, ErrInvalidArchive)
+	}
+}
@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// localSource represents the regexp to match the source of a module stored
+// in the same repository i.e. "../modules/vpc" in HCL or JSON configuration.
+var localSource = regexp.MustCompile(`(?:\bsource\s*=|"source"\s*:)\s*"(\.\.?/[^"]*)"`)
+
+// Changes represents the plugin configuration for only
+// running the directories affected by the changes in a build.
+type Changes struct {
+	// git ref to compare the commit against i.e. "origin/main"
+	BaseRef string
+	// git commit for the build i.e. "$VELA_BUILD_COMMIT"
+	Commit string
+	// if set, only run the directories affected by the changes
+	Only bool
+}
+
+// Filter returns the directories containing or using
+// local modules containing the files changed by the commit.
+func (c *Changes) Filter(ctx context.Context, dirs []string) ([]string, error) {
+	logrus.Tracef("detecting changes between %s and %s", c.BaseRef, c.Commit)
+
+	// capture the files changed by the commit
+	out, err := outputCmd(ctx, diffCmd(ctx, c.BaseRef, c.Commit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect changes: %w", err)
+	}
+
+	var files []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+
+	for scanner.Scan() {
+		files = append(files, scanner.Text())
+	}
+
+	return affected(dirs, files)
+}
+
+// Validate verifies the Changes is properly configured.
+func (c *Changes) Validate() error {
+	logrus.Trace("validating changes plugin configuration")
+
+	// check if only changed directories should run
+	if !c.Only {
+		return nil
+	}
+
+	// verify BaseRef is provided
+	if len(c.BaseRef) == 0 {
+		return fmt.Errorf("no base ref provided for changed_only")
+	}
+
+	// verify Commit is provided
+	if len(c.Commit) == 0 {
+		return fmt.Errorf("no commit provided for changed_only")
+	}
+
+	return nil
+}
+
+// affected is a helper function to return the directories
+// containing or using local modules containing the files.
+func affected(dirs, files []string) ([]string, error) {
+	// variable to store the directories with changed configuration
+	changed := make(map[string]bool)
+
+	for _, file := range files {
+		// skip any files that aren't terraform configuration or variables
+		if !isConfig(file) {
+			continue
+		}
+
+		changed[filepath.Dir(filepath.Clean(file))] = true
+	}
+
+	var result []string
+
+	for _, dir := range dirs {
+		modules, err := moduleDirs(filepath.Clean(dir), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		// check if the directory or any of its local modules changed
+		if !slices.ContainsFunc(modules, func(m string) bool { return changed[m] }) {
+			logrus.Infof("skipping %s with no changes", dir)
+
+			continue
+		}
+
+		result = append(result, dir)
+	}
+
+	return result, nil
+}
+
+// moduleDirs is a helper function to return the directory
+// and the directories of the local modules it uses.
+func moduleDirs(dir string, seen []string) ([]string, error) {
+	// check if the directory was already visited
+	if slices.Contains(seen, dir) {
+		return seen, nil
+	}
+
+	seen = append(seen, dir)
+
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	var files []string
+
+	for _, pattern := range []string{"*.tf", "*.tf.json"} {
+		matches, err := afero.Glob(a, filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, matches...)
+	}
+
+	for _, file := range files {
+		b, err := a.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		// ignore any commented out module sources
+		for _, match := range localSource.FindAllSubmatch(stripComments(b), -1) {
+			seen, err = moduleDirs(filepath.Join(dir, string(match[1])), seen)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return seen, nil
+}
+
+// isConfig is a helper function to check if the
+// file contains terraform configuration or variables.
+func isConfig(file string) bool {
+	for _, ext := range []string{".tf", ".tf.json", ".tfvars", ".tfvars.json"} {
+		if strings.HasSuffix(file, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// diffCmd is a helper function to list the files
+// changed between the base ref and the commit.
+func diffCmd(ctx context.Context, base, commit string) *exec.Cmd {
+	logrus.Trace("creating git diff command")
+
+	return exec.CommandContext(
+		ctx,
+		"git",
+		"diff",
+		"--name-only",
+		"--relative",
+		fmt.Sprintf("%s...%s", base, commit),
+	)
+}
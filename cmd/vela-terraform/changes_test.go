@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"os/exec"
+	"slices"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestTerraform_Changes_Validate(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		changes *Changes
+		failure bool
+	}{
+		{
+			changes: &Changes{},
+			failure: false,
+		},
+		{
+			changes: &Changes{BaseRef: "origin/main", Commit: "7fd1a60", Only: true},
+			failure: false,
+		},
+		{
+			changes: &Changes{Commit: "7fd1a60", Only: true},
+			failure: true,
+		},
+		{
+			changes: &Changes{BaseRef: "origin/main", Only: true},
+			failure: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		err := test.changes.Validate()
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("Validate should have returned err")
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Validate returned err: %v", err)
+		}
+	}
+}
+
+func TestTerraform_affected(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	files := map[string]string{
+		"stacks/app/prod/main.tf": `module "vpc" {
+  source = "../../../modules/vpc"
+}
+
+terraform {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+  }
+}
+`,
+		"stacks/db/prod/main.tf": `module "db" { source = "git::https://github.com/foo/bar.git" }
+# module "vpc" { source = "../../../modules/vpc" }
+`,
+		"stacks/dns/prod/main.tf.json": `{"module": {"zone": {"source": "../../../modules/zone"}}}`,
+		"modules/vpc/main.tf":          `module "subnets" { source = "./subnets" }`,
+		"modules/vpc/subnets/main.tf":  "",
+		"modules/zone/main.tf":         "",
+	}
+
+	for name, content := range files {
+		err := afero.WriteFile(appFS, name, []byte(content), 0644)
+		if err != nil {
+			t.Errorf("unable to create file %s: %v", name, err)
+		}
+	}
+
+	dirs := []string{"stacks/app/prod", "stacks/db/prod", "stacks/dns/prod"}
+
+	// setup tests
+	tests := []struct {
+		changed []string
+		want    []string
+	}{
+		{
+			changed: []string{"modules/vpc/subnets/variables.tf"},
+			want:    []string{"stacks/app/prod"},
+		},
+		{
+			changed: []string{"stacks/dns/prod/prod.tfvars", "stacks/db/prod/README.md"},
+			want:    []string{"stacks/dns/prod"},
+		},
+		{
+			changed: []string{"modules/zone/main.tf"},
+			want:    []string{"stacks/dns/prod"},
+		},
+		{
+			changed: []string{"README.md"},
+			want:    nil,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := affected(dirs, test.changed)
+		if err != nil {
+			t.Errorf("affected returned err: %v", err)
+		}
+
+		if !slices.Equal(got, test.want) {
+			t.Errorf("affected for %v is %v, want %v", test.changed, got, test.want)
+		}
+	}
+}
+
+func TestTerraform_diffCmd(t *testing.T) {
+	// setup types
+	want := exec.CommandContext(
+		t.Context(),
+		"git",
+		"diff",
+		"--name-only",
+		"--relative",
+		"origin/main...7fd1a60",
+	)
+
+	got := diffCmd(t.Context(), "origin/main", "7fd1a60")
+	if got.Path != want.Path {
+		t.Errorf("diffCmd path is %v, want %v", got.Path, want.Path)
+	}
+
+	if !slices.Equal(got.Args, want.Args) {
+		t.Errorf("diffCmd args is %v, want %v", got.Args, want.Args)
+	}
+}
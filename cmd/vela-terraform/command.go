@@ -5,47 +5,88 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/sirupsen/logrus"
 )
 
+// outputKey represents the context key for
+// the writer commands send their output to.
+type outputKey struct{}
+
+// withOutput returns a copy of the context which sends the
+// output of any commands run with it to the provided writer.
+func withOutput(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, outputKey{}, w)
+}
+
+// streams is a helper function to return the writers masking
+// the secrets for the stdout and stderr of commands run with ctx.
+func streams(ctx context.Context) (io.Writer, io.Writer) {
+	m := masker(ctx)
+
+	// check if the output was redirected for the context
+	w, ok := ctx.Value(outputKey{}).(io.Writer)
+	if ok {
+		// share the writer so the output is written by one goroutine at a time
+		w = m.Writer(w)
+
+		return w, w
+	}
+
+	return m.Writer(os.Stdout), m.Writer(os.Stderr)
+}
+
+// stdout is a helper function to return the
+// writer for the output of commands run with ctx.
+func stdout(ctx context.Context) io.Writer {
+	w, _ := streams(ctx)
+
+	return w
+}
+
 // execCmd is a helper function to
 // run the provided command.
-func execCmd(e *exec.Cmd) error {
-	logrus.Tracef("executing cmd %s", strings.Join(e.Args, " "))
+func execCmd(ctx context.Context, e *exec.Cmd) error {
+	logrus.Tracef("executing cmd %s", masker(ctx).Mask(strings.Join(e.Args, " ")))
 
-	// set command stdout to OS stdout
-	e.Stdout = os.Stdout
-	// set command stderr to OS stderr
-	e.Stderr = os.Stderr
+	// set command stdout and stderr to the output for the context
+	e.Stdout, e.Stderr = streams(ctx)
+	defer flush(e.Stdout, e.Stderr)
 
 	// output "trace" string for command
-	fmt.Println("$", strings.Join(e.Args, " "))
+	fmt.Fprintln(e.Stdout, "$", strings.Join(e.Args, " "))
 
 	return e.Run()
 }
 
 // getCmd is a helper function to retrieve
 // the terraform modules required for the files.
-func getCmd(ctx context.Context, dir string) *exec.Cmd {
+func getCmd(ctx context.Context, dir string, v *semver.Version) *exec.Cmd {
 	logrus.Trace("creating terraform get command")
 
 	// terraform binary name
-	name := _terraform
+	name := terraformBin
 
 	// variable to store flags for command
 	var args []string
 
-	// check if Directory is provided
-	if dir != "." {
+	// check if Directory is provided and terraform version supports chdir
+	if dir != "." && SupportsChdir(v) {
 		args = append(args, fmt.Sprintf("-chdir=%s", dir))
 	}
 
 	args = append(args, "get")
 
+	// check if Directory is provided and terraform version doesn't support chdir
+	if dir != "." && !SupportsChdir(v) {
+		args = append(args, dir)
+	}
+
 	return exec.CommandContext(ctx,
 		name,
 		args...,
@@ -63,5 +104,22 @@ func versionCmd(ctx context.Context) *exec.Cmd {
 	// add flag for version kubectl command
 	flags = append(flags, "version")
 
-	return exec.CommandContext(ctx, _terraform, flags...)
+	return exec.CommandContext(ctx, terraformBin, flags...)
+}
+
+// outputCmd is a helper function to run the
+// provided command and capture its output.
+func outputCmd(ctx context.Context, e *exec.Cmd) ([]byte, error) {
+	logrus.Tracef("executing cmd %s", masker(ctx).Mask(strings.Join(e.Args, " ")))
+
+	w, errW := streams(ctx)
+	defer flush(w, errW)
+
+	// set command stderr to the output for the context
+	e.Stderr = errW
+
+	// output "trace" string for command
+	fmt.Fprintln(w, "$", strings.Join(e.Args, " "))
+
+	return e.Output()
 }
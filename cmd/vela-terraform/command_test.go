@@ -3,26 +3,92 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
 	"os/exec"
 	"slices"
 	"testing"
+
+	"github.com/Masterminds/semver/v3"
 )
 
 func TestTerraform_execCmd(t *testing.T) {
 	// setup types
 	e := exec.CommandContext(t.Context(), "echo", "hello")
 
-	err := execCmd(e)
+	err := execCmd(t.Context(), e)
+	if err != nil {
+		t.Errorf("execCmd returned err: %v", err)
+	}
+}
+
+func TestTerraform_execCmd_withOutput(t *testing.T) {
+	// setup types
+	w := new(bytes.Buffer)
+
+	e := exec.CommandContext(t.Context(), "echo", "hello")
+
+	err := execCmd(withOutput(t.Context(), w), e)
 	if err != nil {
 		t.Errorf("execCmd returned err: %v", err)
 	}
+
+	want := "$ echo hello\nhello\n"
+
+	if w.String() != want {
+		t.Errorf("execCmd output is %q, want %q", w.String(), want)
+	}
+}
+
+func TestTerraform_getCmd(t *testing.T) {
+	v, _ := semver.NewVersion("1.0.0")
+	dir := "foobar/"
+
+	//nolint:gosec // ignore G204
+	want := exec.CommandContext(
+		t.Context(),
+		terraformBin,
+		fmt.Sprintf("-chdir=%s", dir),
+		"get",
+	)
+
+	got := getCmd(t.Context(), dir, v)
+	if got.Path != want.Path {
+		t.Errorf("getCmd path is %v, want %v", got.Path, want.Path)
+	}
+
+	if !slices.Equal(got.Args, want.Args) {
+		t.Errorf("getCmd args is %v, want %v", got.Args, want.Args)
+	}
+}
+
+func TestTerraform_getCmd_tf13(t *testing.T) {
+	v, _ := semver.NewVersion("0.13.0")
+	dir := "foobar/"
+
+	//nolint:gosec // ignore G204
+	want := exec.CommandContext(
+		t.Context(),
+		terraformBin,
+		"get",
+		dir,
+	)
+
+	got := getCmd(t.Context(), dir, v)
+	if got.Path != want.Path {
+		t.Errorf("getCmd path is %v, want %v", got.Path, want.Path)
+	}
+
+	if !slices.Equal(got.Args, want.Args) {
+		t.Errorf("getCmd args is %v, want %v", got.Args, want.Args)
+	}
 }
 
 func TestTerraform_versionCmd(t *testing.T) {
 	// setup types
 	want := exec.CommandContext(
 		t.Context(),
-		_terraform,
+		terraformBin,
 		"version",
 	)
 
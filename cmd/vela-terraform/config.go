@@ -3,12 +3,14 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os/user"
 	"path/filepath"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
 )
 
 // netrcFile represents an empty .netrc config file.
@@ -18,27 +20,74 @@ login %s
 password %s
 `
 
+// netrcDefault represents the default entry of a .netrc config file.
+const netrcDefault = `
+default
+login %s
+password %s
+`
+
+// credentialsFile represents the path of the terraform credentials
+// file in the home directory, which terraform merges with any CLI config.
+const credentialsFile = ".terraform.d/credentials.tfrc.json"
+
 type (
 	// Config holds input parameters for the plugin.
 	Config struct {
-		// action to perform with Terraform
-		Action string
+		// actions to perform with Terraform in order
+		Actions []string
 		// Netrc is credentials for cloning
 		Netrc *Netrc
+		// raw input of the YAML or JSON list of additional netrc entries
+		RawNetrcs string
+		// additional netrc entries parsed from the raw input and secret files
+		netrcs []*Netrc
+		// raw input of the map of registry hosts to API tokens
+		RawRegistryCredentials string
+		// registry hosts mapped to API tokens parsed from the raw input
+		registryCredentials map[string]string
+		// SSH is credentials for cloning over SSH
+		SSH *SSH
 	}
 
 	// Netrc is credentials for cloning.
 	Netrc struct {
-		Machine  string
-		Login    string
-		Password string
+		Machine  string `yaml:"machine"`
+		Login    string `yaml:"login"`
+		Password string `yaml:"password"`
+		Account  string `yaml:"account"`
+	}
+
+	// cliCredentials represents the credentials for a host in the terraform credentials file.
+	cliCredentials struct {
+		Token string `json:"token"`
 	}
 )
 
 var appFS = afero.NewOsFs()
 
-// Write creates a .netrc file with the credentials provided in the plugin environment.
+// Write creates the .netrc, terraform CLI config and SSH
+// files with the credentials provided in the plugin environment.
 func (c *Config) Write() error {
+	// capture home directory for the credential files
+	home := homeDir()
+
+	err := c.writeNetrc(home)
+	if err != nil {
+		return err
+	}
+
+	err = c.writeCredentials(home)
+	if err != nil {
+		return err
+	}
+
+	return c.writeSSH(home)
+}
+
+// writeNetrc creates a .netrc file in the home directory with the credentials
+// provided in the plugin environment merged into any existing entries.
+func (c *Config) writeNetrc(home string) error {
 	logrus.Trace("writing .netrc credentials file")
 
 	// use custom filesystem which enables us to test
@@ -46,21 +95,29 @@ func (c *Config) Write() error {
 		Fs: appFS,
 	}
 
-	// create the .netrc file from the provided configuration
-	file := fmt.Sprintf(netrcFile, c.Netrc.Machine, c.Netrc.Login, c.Netrc.Password)
+	// create full path for .netrc file
+	path := filepath.Join(home, ".netrc")
 
-	// set default home directory for root user
-	home := "/root"
+	// variable to store the content of any existing .netrc file
+	var content string
 
-	// capture current user running commands
-	u, err := user.Current()
-	if err == nil {
-		// set home directory to current user
-		home = u.HomeDir
+	// check if a .netrc file already exists
+	ok, err := a.Exists(path)
+	if err != nil {
+		return err
 	}
 
-	// create full path for .netrc file
-	path := filepath.Join(home, ".netrc")
+	if ok {
+		b, err := a.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		content = string(b)
+	}
+
+	// update the .netrc content with the provided configuration
+	content = mergeNetrc(content, append([]*Netrc{c.Netrc}, c.netrcs...)...)
 
 	// send Filesystem call to create directory path for .netrc file
 	err = a.Fs.MkdirAll(filepath.Dir(path), 0777)
@@ -68,7 +125,125 @@ func (c *Config) Write() error {
 		return err
 	}
 
-	return a.WriteFile(filepath.Join(home, ".netrc"), []byte(file), 0600)
+	return a.WriteFile(path, []byte(content), 0600)
+}
+
+// writeCredentials creates the terraform credentials file in the home directory
+// with the registry credentials merged into any existing credentials.
+func (c *Config) writeCredentials(home string) error {
+	// check if any registry credentials were provided
+	if len(c.registryCredentials) == 0 {
+		return nil
+	}
+
+	logrus.Trace("writing terraform credentials file")
+
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	// create full path for the credentials file
+	path := filepath.Join(home, credentialsFile)
+
+	// variables to store the contents of any existing credentials file
+	config := make(map[string]json.RawMessage)
+	credentials := make(map[string]json.RawMessage)
+
+	// check if a credentials file already exists i.e. from "terraform login"
+	ok, err := a.Exists(path)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		b, err := a.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		err = json.Unmarshal(b, &config)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		if raw, ok := config["credentials"]; ok {
+			err = json.Unmarshal(raw, &credentials)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+		}
+	}
+
+	for host, token := range c.registryCredentials {
+		credentials[host], err = json.Marshal(cliCredentials{Token: token})
+		if err != nil {
+			return err
+		}
+	}
+
+	config["credentials"], err = json.Marshal(credentials)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// send Filesystem call to create directory path for the credentials file
+	err = a.Fs.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+
+	// credentials may only be read by the owner
+	err = a.WriteFile(path, append(b, '\n'), 0600)
+	if err != nil {
+		return err
+	}
+
+	// restrict the permissions of an existing credentials file
+	return a.Chmod(path, 0600)
+}
+
+// Unmarshal parses the raw input of the netrc entries and registry
+// credentials and reads the netrc entries from the secret files.
+func (c *Config) Unmarshal() error {
+	logrus.Trace("unmarshalling netrc entries and registry credentials")
+
+	c.netrcs = nil
+	c.registryCredentials = nil
+
+	// check if any netrc entries were passed
+	if len(c.RawNetrcs) > 0 {
+		// JSON is parsed as a subset of YAML
+		err := yaml.Unmarshal([]byte(c.RawNetrcs), &c.netrcs)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal netrc entries: %w", err)
+		}
+	}
+
+	netrcs, err := readNetrcs()
+	if err != nil {
+		return err
+	}
+
+	c.netrcs = append(c.netrcs, netrcs...)
+
+	// check if any credentials were passed
+	if len(c.RawRegistryCredentials) == 0 {
+		return nil
+	}
+
+	// JSON is parsed as a subset of YAML
+	err = yaml.Unmarshal([]byte(c.RawRegistryCredentials), &c.registryCredentials)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal registry credentials: %w", err)
+	}
+
+	return nil
 }
 
 // Validate verifies the Config is properly configured.
@@ -76,9 +251,47 @@ func (c *Config) Validate() error {
 	logrus.Trace("validating config plugin configuration")
 
 	// verify action is provided
-	if len(c.Action) == 0 {
+	if len(c.Actions) == 0 {
 		return fmt.Errorf("no config action provided")
 	}
 
+	// verify a machine is provided for each netrc entry
+	for i, n := range c.netrcs {
+		if n == nil || len(n.Machine) == 0 {
+			return fmt.Errorf("no machine provided for netrc entry %d", i)
+		}
+	}
+
+	// verify an SSH key is provided for the known hosts
+	if c.SSH != nil && len(c.SSH.KnownHosts) > 0 && len(c.SSH.Key) == 0 {
+		return fmt.Errorf("no ssh key provided for ssh known hosts")
+	}
+
+	// verify the hosts can be verified for the SSH key
+	if c.SSH != nil && len(c.SSH.Key) > 0 && len(c.SSH.KnownHosts) == 0 && !c.SSH.TrustOnFirstUse {
+		return fmt.Errorf("no ssh known hosts provided for ssh key, set ssh_trust_on_first_use to trust the hosts on first connection")
+	}
+
+	// verify a token is provided for each registry
+	for host, token := range c.registryCredentials {
+		if len(token) == 0 {
+			return fmt.Errorf("no token provided for registry %s", host)
+		}
+	}
+
 	return nil
 }
+
+// homeDir is a helper function to return the
+// home directory of the user running commands.
+func homeDir() string {
+	// capture current user running commands
+	u, err := user.Current()
+	if err == nil {
+		// set home directory to current user
+		return u.HomeDir
+	}
+
+	// set default home directory for root user
+	return "/root"
+}
@@ -3,6 +3,8 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/afero"
@@ -27,6 +29,169 @@ func TestTerraform_Config_Write(t *testing.T) {
 	}
 }
 
+func TestTerraform_Config_Write_Merge(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	path := filepath.Join(homeDir(), ".netrc")
+
+	existing := `machine github.com login stale account ci password old
+machine registry.example.com login robot password keep
+macdef init
+cd /pub
+bin
+
+default login anonymous password guest
+`
+
+	err := afero.WriteFile(appFS, path, []byte(existing), 0600)
+	if err != nil {
+		t.Errorf("unable to create file: %v", err)
+	}
+
+	err = afero.WriteFile(appFS, "/vela/secrets/terraform/netrc/artifactory", []byte("machine artifactory.example.com login deployer password xyz789"), 0600)
+	if err != nil {
+		t.Errorf("unable to create file: %v", err)
+	}
+
+	// setup types
+	c := &Config{
+		Netrc: &Netrc{
+			Machine:  "github.com",
+			Login:    "octocat",
+			Password: "mypassword",
+		},
+		RawNetrcs: `[{"machine": "ghe.example.com", "login": "octocat", "password": "abc123"}]`,
+	}
+
+	err = c.Unmarshal()
+	if err != nil {
+		t.Errorf("Unmarshal returned err: %v", err)
+	}
+
+	err = c.Write()
+	if err != nil {
+		t.Errorf("Write returned err: %v", err)
+	}
+
+	got, err := afero.ReadFile(appFS, path)
+	if err != nil {
+		t.Errorf("unable to read file: %v", err)
+	}
+
+	want := `machine github.com login octocat account ci password mypassword
+machine registry.example.com login robot password keep
+macdef init
+cd /pub
+bin
+
+machine ghe.example.com
+login octocat
+password abc123
+
+machine artifactory.example.com
+login deployer
+password xyz789
+
+default login anonymous password guest
+`
+
+	if string(got) != want {
+		t.Errorf(".netrc is %s, want %s", got, want)
+	}
+}
+
+func TestTerraform_Config_Write_RegistryCredentials(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	t.Setenv("TF_CLI_CONFIG_FILE", "/root/.terraformrc")
+
+	path := filepath.Join(homeDir(), credentialsFile)
+
+	// create credentials file from "terraform login"
+	existing := `{"credentials": {"app.terraform.io": {"token": "old"}, "tfe.example.com": {"token": "keep"}}}`
+
+	err := afero.WriteFile(appFS, path, []byte(existing), 0644)
+	if err != nil {
+		t.Errorf("unable to create file: %v", err)
+	}
+
+	// setup types
+	c := &Config{
+		Netrc:                  &Netrc{},
+		RawRegistryCredentials: "app.terraform.io: abc123\nregistry.example.com: xyz789\n",
+	}
+
+	err = c.Unmarshal()
+	if err != nil {
+		t.Errorf("Unmarshal returned err: %v", err)
+	}
+
+	err = c.Write()
+	if err != nil {
+		t.Errorf("Write returned err: %v", err)
+	}
+
+	// verify the existing CLI config is kept
+	if got := os.Getenv("TF_CLI_CONFIG_FILE"); got != "/root/.terraformrc" {
+		t.Errorf("TF_CLI_CONFIG_FILE is %s, want %s", got, "/root/.terraformrc")
+	}
+
+	info, err := appFS.Stat(path)
+	if err != nil {
+		t.Errorf("unable to stat file: %v", err)
+	}
+
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("credentials file mode is %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+
+	got, err := afero.ReadFile(appFS, path)
+	if err != nil {
+		t.Errorf("unable to read file: %v", err)
+	}
+
+	want := `{
+  "credentials": {
+    "app.terraform.io": {
+      "token": "abc123"
+    },
+    "registry.example.com": {
+      "token": "xyz789"
+    },
+    "tfe.example.com": {
+      "token": "keep"
+    }
+  }
+}
+`
+
+	if string(got) != want {
+		t.Errorf("credentials file is %s, want %s", got, want)
+	}
+}
+
+func TestTerraform_Config_Write_NoRegistryCredentials(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	// setup types
+	c := &Config{
+		Netrc: &Netrc{},
+	}
+
+	err := c.Write()
+	if err != nil {
+		t.Errorf("Write returned err: %v", err)
+	}
+
+	ok, _ := afero.Exists(appFS, filepath.Join(homeDir(), credentialsFile))
+	if ok {
+		t.Errorf("Write should not have created %s", credentialsFile)
+	}
+}
+
 func TestKubernetes_Config_Write_Error(t *testing.T) {
 	// setup filesystem
 	appFS = afero.NewReadOnlyFs(afero.NewMemMapFs())
@@ -49,7 +214,7 @@ func TestKubernetes_Config_Write_Error(t *testing.T) {
 func TestTerraform_Config_Validate(t *testing.T) {
 	// setup types
 	c := &Config{
-		Action: "apply",
+		Actions: []string{"apply"},
 	}
 
 	err := c.Validate()
@@ -67,3 +232,54 @@ func TestTerraform_Config_Validate_Error(t *testing.T) {
 		t.Errorf("Write should have returned err")
 	}
 }
+
+func TestTerraform_Config_Validate_RegistryCredentials(t *testing.T) {
+	// setup types
+	c := &Config{
+		Actions:                []string{"apply"},
+		RawRegistryCredentials: `{"app.terraform.io": ""}`,
+	}
+
+	err := c.Unmarshal()
+	if err != nil {
+		t.Errorf("Unmarshal returned err: %v", err)
+	}
+
+	err = c.Validate()
+	if err == nil {
+		t.Errorf("Validate should have returned err")
+	}
+}
+
+func TestTerraform_Config_Unmarshal_Error(t *testing.T) {
+	// setup types
+	c := &Config{
+		RawRegistryCredentials: "- app.terraform.io",
+	}
+
+	err := c.Unmarshal()
+	if err == nil {
+		t.Errorf("Unmarshal should have returned err")
+	}
+}
+
+func TestTerraform_Config_Validate_Netrcs(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	// setup types
+	c := &Config{
+		Actions:   []string{"apply"},
+		RawNetrcs: "- login: octocat\n  password: abc123\n",
+	}
+
+	err := c.Unmarshal()
+	if err != nil {
+		t.Errorf("Unmarshal returned err: %v", err)
+	}
+
+	err = c.Validate()
+	if err == nil {
+		t.Errorf("Validate should have returned err")
+	}
+}
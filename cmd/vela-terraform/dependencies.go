@@ -0,0 +1,350 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrDependencyCycle defines the error type when the
+// dependencies between directories contain a cycle.
+var ErrDependencyCycle = errors.New("dependency cycle detected")
+
+var (
+	// backendBlock represents the regexp to match the start of a backend block.
+	backendBlock = regexp.MustCompile(`\bbackend\s+"[^"]+"\s*\{`)
+	// remoteStateBlock represents the regexp to match the start of a terraform_remote_state block.
+	remoteStateBlock = regexp.MustCompile(`\bdata\s+"terraform_remote_state"\s+"[^"]+"\s*\{`)
+	// stateAttribute represents the regexp to match the attribute locating a state i.e. "key" or "path".
+	stateAttribute = regexp.MustCompile(`\b(key|path)\s*=\s*"([^"]*)"`)
+)
+
+// Dependencies returns the directories each of the directories depends on
+// from the manifest and the terraform_remote_state data sources if enabled.
+func (d *Directories) Dependencies(dirs []string) (map[string][]string, error) {
+	logrus.Trace("building dependencies for directories")
+
+	deps := make(map[string][]string)
+
+	// check if a manifest was provided
+	if len(d.Manifest) > 0 {
+		manifest, err := readManifest(d.Manifest)
+		if err != nil {
+			return nil, err
+		}
+
+		for dir, dependencies := range manifest {
+			dir = filepath.Clean(dir)
+
+			for _, dep := range dependencies {
+				deps[dir] = appendUnique(deps[dir], filepath.Clean(dep))
+			}
+		}
+	}
+
+	// check if the remote state dependencies should be detected
+	if d.DetectDependencies {
+		detected, err := detectDependencies(dirs)
+		if err != nil {
+			return nil, err
+		}
+
+		for dir, dependencies := range detected {
+			for _, dep := range dependencies {
+				deps[dir] = appendUnique(deps[dir], dep)
+			}
+		}
+	}
+
+	return deps, nil
+}
+
+// readManifest is a helper function to read the
+// dependencies for each directory from the YAML or JSON file.
+func readManifest(path string) (map[string][]string, error) {
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	b, err := a.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// manifests are formatted as "<directory>: [ <dependency> ]"
+	manifest := make(map[string][]string)
+
+	// JSON is parsed as a subset of YAML
+	err = yaml.Unmarshal(b, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse depends_on manifest %s: %w", path, err)
+	}
+
+	return manifest, nil
+}
+
+// detectDependencies is a helper function to map the terraform_remote_state
+// data sources in each directory to the directories storing the state.
+func detectDependencies(dirs []string) (map[string][]string, error) {
+	// variable to store the directory for each state
+	states := make(map[string]string)
+
+	// variable to store the states read by each directory
+	refs := make(map[string][]string)
+
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+
+		src, err := readConfig(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		// ignore any commented out blocks
+		src = stripComments(src)
+
+		backends := blocks(src, backendBlock)
+
+		// directories without a backend use the local state
+		if len(backends) == 0 {
+			states[stateID("path", "terraform.tfstate", dir)] = dir
+		}
+
+		for _, body := range backends {
+			for _, match := range stateAttribute.FindAllStringSubmatch(body, -1) {
+				states[stateID(match[1], match[2], dir)] = dir
+			}
+		}
+
+		for _, body := range blocks(src, remoteStateBlock) {
+			for _, match := range stateAttribute.FindAllStringSubmatch(body, -1) {
+				refs[dir] = append(refs[dir], stateID(match[1], match[2], dir))
+			}
+		}
+	}
+
+	deps := make(map[string][]string)
+
+	for dir, ids := range refs {
+		for _, id := range ids {
+			dep, ok := states[id]
+			if !ok || dep == dir {
+				continue
+			}
+
+			logrus.Debugf("detected dependency of %s on %s", dir, dep)
+
+			deps[dir] = appendUnique(deps[dir], dep)
+		}
+	}
+
+	return deps, nil
+}
+
+// stateID is a helper function to create the identifier
+// for the state located by the attribute in the directory.
+func stateID(attribute, value, dir string) string {
+	// local paths are relative to the directory
+	if attribute == "path" {
+		return fmt.Sprintf("path:%s", filepath.Join(dir, value))
+	}
+
+	return fmt.Sprintf("%s:%s", attribute, value)
+}
+
+// readConfig is a helper function to read all
+// the terraform configuration files in the directory.
+func readConfig(dir string) ([]byte, error) {
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	files, err := afero.Glob(a, filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+
+	var src []byte
+
+	for _, file := range files {
+		b, err := a.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		src = append(append(src, b...), '\n')
+	}
+
+	return src, nil
+}
+
+// blocks is a helper function to return the body of
+// each block in the configuration matching the header.
+func blocks(src []byte, header *regexp.Regexp) []string {
+	var bodies []string
+
+	for _, loc := range header.FindAllIndex(src, -1) {
+		// the header ends with the opening brace of the block
+		bodies = append(bodies, blockBody(src, loc[1]))
+	}
+
+	return bodies
+}
+
+// blockBody is a helper function to return the body of the
+// block in the configuration starting after its opening brace.
+func blockBody(src []byte, start int) string {
+	depth := 1
+
+	for i := start; i < len(src); i++ {
+		switch src[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		}
+
+		if depth == 0 {
+			return string(src[start:i])
+		}
+	}
+
+	return string(src[start:])
+}
+
+// stripComments is a helper function to remove the
+// comments outside of strings from the configuration.
+func stripComments(src []byte) []byte {
+	var (
+		out      []byte
+		inString bool
+	)
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+
+		switch {
+		case inString:
+			// keep any escaped character within the string
+			if c == '\\' && i+1 < len(src) {
+				out = append(out, c, src[i+1])
+				i++
+
+				continue
+			}
+
+			inString = c != '"'
+		case c == '"':
+			inString = true
+		case c == '#' || (c == '/' && i+1 < len(src) && src[i+1] == '/'):
+			// line comments continue until the end of the line
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			// block comments continue until the closing delimiter
+			end := strings.Index(string(src[i+2:]), "*/")
+			if end < 0 {
+				return out
+			}
+
+			i += end + 3
+
+			continue
+		}
+
+		if i < len(src) {
+			out = append(out, src[i])
+		}
+	}
+
+	return out
+}
+
+// sortDirectories is a helper function to order the directories so
+// each runs after its dependencies while keeping the provided order.
+func sortDirectories(dirs []string, deps map[string][]string) ([]string, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	var (
+		order []string
+		state = make(map[string]int)
+		visit func(dir string, path []string) error
+	)
+
+	visit = func(dir string, path []string) error {
+		switch state[dir] {
+		case visiting:
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(append(path, dir), " -> "))
+		case visited:
+			return nil
+		}
+
+		state[dir] = visiting
+
+		for _, dep := range deps[dir] {
+			err := visit(dep, append(path, dir))
+			if err != nil {
+				return err
+			}
+		}
+
+		state[dir] = visited
+
+		order = append(order, dir)
+
+		return nil
+	}
+
+	for _, dir := range dirs {
+		err := visit(dir, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// reverseDependencies is a helper function to return the dependencies
+// with the direction inverted i.e. for destroying directories.
+func reverseDependencies(deps map[string][]string) map[string][]string {
+	reversed := make(map[string][]string)
+
+	for dir, dependencies := range deps {
+		for _, dep := range dependencies {
+			reversed[dep] = appendUnique(reversed[dep], dir)
+		}
+	}
+
+	// sort the dependencies since maps are iterated in random order
+	for dir := range reversed {
+		slices.Sort(reversed[dir])
+	}
+
+	return reversed
+}
+
+// appendUnique is a helper function to append
+// the value when it isn't already in the slice.
+func appendUnique(s []string, v string) []string {
+	if slices.Contains(s, v) {
+		return s
+	}
+
+	return append(s, v)
+}
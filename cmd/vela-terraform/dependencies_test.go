@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestTerraform_Directories_Dependencies_Manifest(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	// setup tests
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "depends_on.yml",
+			content: `stacks/app/:
+  - stacks/network
+  - ./global
+`,
+		},
+		{
+			name:    "depends_on.json",
+			content: `{"stacks/app/": ["stacks/network", "./global"]}`,
+		},
+	}
+
+	want := map[string][]string{
+		"stacks/app": {"stacks/network", "global"},
+	}
+
+	// run tests
+	for _, test := range tests {
+		err := afero.WriteFile(appFS, test.name, []byte(test.content), 0644)
+		if err != nil {
+			t.Errorf("unable to create file: %v", err)
+		}
+
+		d := &Directories{Manifest: test.name}
+
+		got, err := d.Dependencies(nil)
+		if err != nil {
+			t.Errorf("Dependencies returned err: %v", err)
+		}
+
+		if !maps.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("Dependencies for %s is %v, want %v", test.name, got, want)
+		}
+	}
+}
+
+func TestTerraform_Directories_Dependencies_Detect(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	files := map[string]string{
+		"network/main.tf": `terraform {
+  backend "s3" {
+    bucket = "state"
+    key    = "network/terraform.tfstate"
+  }
+}
+`,
+		"app/main.tf": `data "terraform_remote_state" "network" {
+  backend = "s3"
+  config = {
+    bucket = "state"
+    key    = "network/terraform.tfstate"
+  }
+}
+
+data "terraform_remote_state" "global" {
+  backend = "local"
+  config = {
+    path = "../global/terraform.tfstate"
+  }
+}
+`,
+		"global/main.tf": `resource "null_resource" "foo" {}
+
+/*
+data "terraform_remote_state" "app" {
+  backend = "local"
+  config = {
+    path = "../app/terraform.tfstate"
+  }
+}
+*/
+`,
+	}
+
+	for name, content := range files {
+		err := afero.WriteFile(appFS, name, []byte(content), 0644)
+		if err != nil {
+			t.Errorf("unable to create file %s: %v", name, err)
+		}
+	}
+
+	d := &Directories{DetectDependencies: true}
+
+	want := map[string][]string{
+		"app": {"network", "global"},
+	}
+
+	got, err := d.Dependencies([]string{"app", "global", "network"})
+	if err != nil {
+		t.Errorf("Dependencies returned err: %v", err)
+	}
+
+	if !maps.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("Dependencies is %v, want %v", got, want)
+	}
+}
+
+func TestTerraform_sortDirectories(t *testing.T) {
+	// setup types
+	deps := map[string][]string{
+		"app":     {"network", "global"},
+		"network": {"global"},
+	}
+
+	want := []string{"global", "network", "app", "other"}
+
+	got, err := sortDirectories([]string{"app", "network", "global", "other"}, deps)
+	if err != nil {
+		t.Errorf("sortDirectories returned err: %v", err)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("sortDirectories is %v, want %v", got, want)
+	}
+
+	// verify cycles are rejected
+	deps["global"] = []string{"app"}
+
+	_, err = sortDirectories([]string{"app", "network", "global"}, deps)
+	if !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("sortDirectories returned err %v, want %v", err, ErrDependencyCycle)
+	}
+}
+
+func TestTerraform_stripComments(t *testing.T) {
+	// setup types
+	src := `# comment
+terraform { // comment
+  /* block
+  comment */ required_version = "~> 1.5"
+  key = "https://example.com/#/state" # comment
+}
+`
+
+	want := "\nterraform { \n   required_version = \"~> 1.5\"\n  key = \"https://example.com/#/state\" \n}\n"
+
+	got := string(stripComments([]byte(src)))
+
+	if got != want {
+		t.Errorf("stripComments is %q, want %q", got, want)
+	}
+}
+
+func TestTerraform_reverseDependencies(t *testing.T) {
+	// setup types
+	deps := map[string][]string{
+		"app": {"network", "global"},
+		"dns": {"global"},
+	}
+
+	want := map[string][]string{
+		"global":  {"app", "dns"},
+		"network": {"app"},
+	}
+
+	got := reverseDependencies(deps)
+
+	if !maps.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("reverseDependencies is %v, want %v", got, want)
+	}
+}
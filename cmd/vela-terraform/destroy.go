@@ -4,8 +4,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,12 +20,26 @@ import (
 
 const destroyAction = "destroy"
 
+var (
+	// ErrDestroyNotConfirmed defines the error type when
+	// the destroy confirmation doesn't match the expected value.
+	ErrDestroyNotConfirmed = errors.New("destroy not confirmed")
+
+	// ErrDestroyProtected defines the error type when destroy
+	// is run for a protected branch or deployment.
+	ErrDestroyProtected = errors.New("destroy is not allowed")
+)
+
 // Destroy represents the plugin configuration for destroy information.
 type Destroy struct {
 	// skip interactive approval of plan before applying. i.e. "-auto-approve"
 	AutoApprove bool
 	// path to backup the existing state file before modifying. i.e. "-backup=path "
 	Backup string
+	// key for the state in the backend configuration used to confirm destroy
+	BackendKey string
+	// values that must include the workspace, backend key or directory to destroy
+	Confirm []string
 	// terraform file or directory to destroy
 	Directory string
 	// the state file when locking is supported. i.e. -lock=true
@@ -31,19 +50,27 @@ type Destroy struct {
 	NoColor bool
 	// limit the number of parallel resource operations. i.e. "-parallelism=n"
 	Parallelism int
+	// if set, confirm destroy for the directory since it's one of multiple directories
+	perDirectory bool
+	// branch or deployment patterns where destroy is refused. i.e. "main", "prod*"
+	ProtectedPatterns []string
 	// update state prior to checking for differences. i.e. "-refresh=true"
 	Refresh bool
+	// resources to replace, which terraform does not support when destroying
+	Replace []string
 	// path to read and save state (unless state-out is specified). i.e. "-state=path"
 	State string
 	// path to write state to that is different than state. i.e. "-state-out=path"
 	StateOut string
-	// resource to target. i.e. "-target=resource"
-	Target string
+	// resources to target. i.e. "-target=resource"
+	Targets []string
 	// set a variable in the Terraform configuration. i.e. "-var 'foo=bar'"
 	Vars []string
 	// set variables in the Terraform configuration from a file. i.e. "-var-file=foo"
 	VarFiles []string
 	Version  *semver.Version
+	// name of the selected workspace used to confirm destroy
+	Workspace string
 }
 
 // Command formats and outputs the Destroy command from
@@ -116,10 +143,12 @@ func (d *Destroy) Command(ctx context.Context) *exec.Cmd {
 		flags = append(flags, fmt.Sprintf("-state-out=%s", d.StateOut))
 	}
 
-	// check if Target is provided
-	if len(d.Target) > 0 {
-		// add flag for Target from provided destroy command
-		flags = append(flags, fmt.Sprintf("-target=%s", d.Target))
+	// check if Targets is provided
+	if len(d.Targets) > 0 {
+		for _, t := range d.Targets {
+			// add flag for Targets from provided destroy command
+			flags = append(flags, fmt.Sprintf("-target=%s", t))
+		}
 	}
 
 	// check if VarFiles is provided
@@ -146,7 +175,7 @@ func (d *Destroy) Command(ctx context.Context) *exec.Cmd {
 	globalFlags = append(globalFlags, destroyAction)
 
 	//nolint:gosec // ignore G204
-	return exec.CommandContext(ctx, _terraform, append(globalFlags, flags...)...)
+	return exec.CommandContext(ctx, terraformBin, append(globalFlags, flags...)...)
 }
 
 // Exec formats and runs the commands for destroying resources with Terraform.
@@ -157,7 +186,7 @@ func (d *Destroy) Exec(ctx context.Context) error {
 	cmd := d.Command(ctx)
 
 	// run the destroy command for the file
-	err := execCmd(cmd)
+	err := execCmd(ctx, cmd)
 	if err != nil {
 		return err
 	}
@@ -173,5 +202,118 @@ func (d *Destroy) Validate() error {
 		logrus.Warn("terraform destroy will run in current dir")
 	}
 
+	err := d.Allowed()
+	if err != nil {
+		return err
+	}
+
+	return d.Confirmed()
+}
+
+// Allowed verifies destroy is allowed with the
+// flags provided and for the branch or deployment.
+func (d *Destroy) Allowed() error {
+	// terraform rejects replace when destroying
+	if len(d.Replace) > 0 {
+		return fmt.Errorf("%w: replace is not supported with destroy", ErrUnsupportedFlag)
+	}
+
+	return d.Protected()
+}
+
+// Protected verifies destroy is not run for a protected branch or deployment.
+func (d *Destroy) Protected() error {
+	// verify destroy is not run for a protected branch or deployment
+	for _, env := range []string{"VELA_BUILD_BRANCH", "VELA_DEPLOYMENT"} {
+		value := os.Getenv(env)
+
+		// check if the environment variable is provided
+		if len(value) == 0 {
+			continue
+		}
+
+		for _, pattern := range d.ProtectedPatterns {
+			ok, err := path.Match(pattern, value)
+			if err != nil {
+				return fmt.Errorf("invalid protected pattern %s: %w", pattern, err)
+			}
+
+			if ok {
+				return fmt.Errorf("%w: %s %s matches protected pattern %s", ErrDestroyProtected, env, value, pattern)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Confirmed verifies destroy without interactive approval is confirmed.
+func (d *Destroy) Confirmed() error {
+	// check if interactive approval is skipped
+	if !d.AutoApprove {
+		return nil
+	}
+
+	return d.confirmed()
+}
+
+// Planned verifies a plan destroying resources is allowed and confirmed
+// since applying the saved plan file doesn't ask for interactive approval.
+func (d *Destroy) Planned() error {
+	// check if the destroy safeguards are provided
+	if d == nil {
+		return nil
+	}
+
+	err := d.Protected()
+	if err != nil {
+		return err
+	}
+
+	return d.confirmed()
+}
+
+// confirmed is a helper function to verify
+// confirm_destroy includes the confirmation.
+func (d *Destroy) confirmed() error {
+	want := d.Confirmation()
+
+	if !slices.Contains(d.Confirm, want) {
+		return fmt.Errorf("%w: confirm_destroy must include %q", ErrDestroyNotConfirmed, want)
+	}
+
 	return nil
 }
+
+// Confirmation returns the value confirm_destroy must match
+// derived from the workspace, backend key or directory.
+func (d *Destroy) Confirmation() string {
+	// check if destroy runs in one of multiple directories sharing the workspace and backend key
+	if d.perDirectory {
+		return filepath.Clean(d.Directory)
+	}
+
+	// check if a workspace other than the default is selected
+	for _, ws := range []string{d.Workspace, os.Getenv("TF_WORKSPACE")} {
+		if len(ws) > 0 && ws != "default" {
+			return ws
+		}
+	}
+
+	// check if BackendKey is provided
+	if len(d.BackendKey) > 0 {
+		return d.BackendKey
+	}
+
+	dir := filepath.Clean(d.Directory)
+
+	// check if destroy will run in the current directory
+	if dir == "." {
+		wd, err := os.Getwd()
+		if err == nil {
+			return filepath.Base(wd)
+		}
+	}
+
+	return dir
+}
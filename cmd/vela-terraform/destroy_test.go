@@ -3,6 +3,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
 	"slices"
@@ -26,7 +27,7 @@ func TestTerraform_Destroy_Command(t *testing.T) {
 		Refresh:     true,
 		State:       "state.tf",
 		StateOut:    "stateout.tf",
-		Target:      "target.tf",
+		Targets:     []string{"target1.tf", "target2.tf"},
 		Vars:        []string{"foo=bar", "bar=foo"},
 		VarFiles:    []string{"vars1.tf", "vars2.tf"},
 		Version:     v,
@@ -35,7 +36,7 @@ func TestTerraform_Destroy_Command(t *testing.T) {
 	//nolint:gosec // ignore G204
 	want := exec.CommandContext(
 		t.Context(),
-		_terraform,
+		terraformBin,
 		fmt.Sprintf("-chdir=%s", d.Directory),
 		destroyAction,
 		"-auto-approve",
@@ -47,7 +48,8 @@ func TestTerraform_Destroy_Command(t *testing.T) {
 		"-refresh=true",
 		fmt.Sprintf("-state=%s", d.State),
 		fmt.Sprintf("-state-out=%s", d.StateOut),
-		fmt.Sprintf("-target=%s", d.Target),
+		fmt.Sprintf("-target=%s", d.Targets[0]),
+		fmt.Sprintf("-target=%s", d.Targets[1]),
 		fmt.Sprintf("-var-file=%s", d.VarFiles[0]),
 		fmt.Sprintf("-var-file=%s", d.VarFiles[1]),
 		fmt.Sprintf("-var=%s", d.Vars[0]),
@@ -78,7 +80,7 @@ func TestTerraform_Destroy_Command_tf13(t *testing.T) {
 		Refresh:     true,
 		State:       "state.tf",
 		StateOut:    "stateout.tf",
-		Target:      "target.tf",
+		Targets:     []string{"target1.tf", "target2.tf"},
 		Vars:        []string{"foo=bar", "bar=foo"},
 		VarFiles:    []string{"vars1.tf", "vars2.tf"},
 		Version:     v,
@@ -87,7 +89,7 @@ func TestTerraform_Destroy_Command_tf13(t *testing.T) {
 	//nolint:gosec // ignore G204
 	want := exec.CommandContext(
 		t.Context(),
-		_terraform,
+		terraformBin,
 		destroyAction,
 		"-auto-approve",
 		fmt.Sprintf("-backup=%s", d.Backup),
@@ -98,7 +100,8 @@ func TestTerraform_Destroy_Command_tf13(t *testing.T) {
 		"-refresh=true",
 		fmt.Sprintf("-state=%s", d.State),
 		fmt.Sprintf("-state-out=%s", d.StateOut),
-		fmt.Sprintf("-target=%s", d.Target),
+		fmt.Sprintf("-target=%s", d.Targets[0]),
+		fmt.Sprintf("-target=%s", d.Targets[1]),
 		fmt.Sprintf("-var-file=%s", d.VarFiles[0]),
 		fmt.Sprintf("-var-file=%s", d.VarFiles[1]),
 		fmt.Sprintf("-var=%s", d.Vars[0]),
@@ -155,3 +158,130 @@ func TestTerraform_Destroy_Validate(t *testing.T) {
 		}
 	}
 }
+
+func TestTerraform_Destroy_Validate_Confirm(t *testing.T) {
+	v, _ := semver.NewVersion("1.0.0")
+	// setup tests
+	tests := []struct {
+		destroy *Destroy
+		want    error
+	}{
+		{
+			destroy: &Destroy{AutoApprove: true, Directory: "foobar/", Version: v},
+			want:    ErrDestroyNotConfirmed,
+		},
+		{
+			destroy: &Destroy{AutoApprove: true, Confirm: []string{"barfoo"}, Directory: "foobar/", Version: v},
+			want:    ErrDestroyNotConfirmed,
+		},
+		{
+			destroy: &Destroy{AutoApprove: true, Confirm: []string{"foobar"}, Directory: "foobar/", Version: v},
+			want:    nil,
+		},
+		{
+			destroy: &Destroy{AutoApprove: true, BackendKey: "prod/network.tfstate", Confirm: []string{"prod/network.tfstate"}, Directory: "foobar/", Version: v},
+			want:    nil,
+		},
+		{
+			destroy: &Destroy{Directory: "foobar/", Version: v},
+			want:    nil,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		err := test.destroy.Validate()
+		if !errors.Is(err, test.want) {
+			t.Errorf("Validate returned err %v, want %v", err, test.want)
+		}
+	}
+}
+
+func TestTerraform_Destroy_Validate_Protected(t *testing.T) {
+	v, _ := semver.NewVersion("1.0.0")
+	// setup tests
+	tests := []struct {
+		env   string
+		value string
+		want  error
+	}{
+		{env: "VELA_BUILD_BRANCH", value: "main", want: ErrDestroyProtected},
+		{env: "VELA_BUILD_BRANCH", value: "release/v1", want: ErrDestroyProtected},
+		{env: "VELA_BUILD_BRANCH", value: "feature/foo", want: nil},
+		{env: "VELA_DEPLOYMENT", value: "production", want: ErrDestroyProtected},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Setenv("VELA_BUILD_BRANCH", "")
+		t.Setenv("VELA_DEPLOYMENT", "")
+		t.Setenv(test.env, test.value)
+
+		d := &Destroy{
+			Directory:         "foobar/",
+			ProtectedPatterns: []string{"main", "release/*", "prod*"},
+			Version:           v,
+		}
+
+		err := d.Validate()
+		if !errors.Is(err, test.want) {
+			t.Errorf("Validate for %s=%s returned err %v, want %v", test.env, test.value, err, test.want)
+		}
+	}
+}
+
+func TestTerraform_Destroy_Confirmation(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		destroy   *Destroy
+		workspace string
+		want      string
+	}{
+		{
+			destroy: &Destroy{Directory: "stacks/network/prod/"},
+			want:    "stacks/network/prod",
+		},
+		{
+			destroy: &Destroy{BackendKey: "network.tfstate", Directory: "stacks/network/prod/"},
+			want:    "network.tfstate",
+		},
+		{
+			destroy:   &Destroy{BackendKey: "network.tfstate", Directory: "stacks/network/prod/"},
+			workspace: "prod",
+			want:      "prod",
+		},
+		{
+			destroy:   &Destroy{Directory: "stacks/network/prod/"},
+			workspace: "default",
+			want:      "stacks/network/prod",
+		},
+		{
+			destroy:   &Destroy{BackendKey: "network.tfstate", Directory: "stacks/network/prod/", Workspace: "staging"},
+			workspace: "prod",
+			want:      "staging",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Setenv("TF_WORKSPACE", test.workspace)
+
+		got := test.destroy.Confirmation()
+		if got != test.want {
+			t.Errorf("Confirmation is %v, want %v", got, test.want)
+		}
+	}
+}
+
+func TestTerraform_Destroy_Validate_Replace(t *testing.T) {
+	// setup types
+	d := &Destroy{
+		Directory: "foobar/",
+		Replace:   []string{"aws_instance.web"},
+	}
+
+	err := d.Validate()
+	if !errors.Is(err, ErrUnsupportedFlag) {
+		t.Errorf("Validate returned err %v, want %v", err, ErrUnsupportedFlag)
+	}
+}
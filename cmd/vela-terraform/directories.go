@@ -0,0 +1,250 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+var (
+	// ErrNoDirectories defines the error type when a
+	// directories pattern doesn't match any directories.
+	ErrNoDirectories = errors.New("no directories matched")
+
+	// ErrDirectoriesFailed defines the error type when
+	// running the plugin failed for any of the directories.
+	ErrDirectoriesFailed = errors.New("failed to run directories")
+)
+
+// Directories represents the plugin configuration for running
+// the plugin in multiple directories i.e. root modules of a monorepo.
+type Directories struct {
+	// number of directories to run in parallel
+	Concurrency int
+	// if set, run the remaining directories when one fails
+	ContinueOnError bool
+	// if set, detect dependencies from terraform_remote_state data sources
+	DetectDependencies bool
+	// path to the YAML or JSON file with the dependencies of each directory
+	Manifest string
+	// paths or glob patterns for the directories to run i.e. "stacks/*/prod"
+	Patterns []string
+}
+
+// Expand returns the directories matching the
+// patterns in the order they were provided.
+func (d *Directories) Expand() ([]string, error) {
+	logrus.Trace("expanding directories")
+
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	var dirs []string
+
+	for _, pattern := range d.Patterns {
+		matches, err := afero.Glob(a, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid directories pattern %s: %w", pattern, err)
+		}
+
+		// variable to store if the pattern matched a directory
+		var found bool
+
+		for _, match := range matches {
+			// skip any files matching the pattern
+			ok, err := a.IsDir(match)
+			if err != nil || !ok {
+				continue
+			}
+
+			found = true
+
+			// skip any directories matched by a previous pattern
+			if slices.Contains(dirs, match) {
+				continue
+			}
+
+			dirs = append(dirs, match)
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrNoDirectories, pattern)
+		}
+	}
+
+	return dirs, nil
+}
+
+// Run calls fn for each of the directories after its dependencies
+// and outputs a summary with the result for each directory.
+func (d *Directories) Run(ctx context.Context, dirs []string, deps map[string][]string, fn func(context.Context, string) error) error {
+	logrus.Debugf("running %d directories with concurrency %d", len(dirs), d.Concurrency)
+
+	// variable to store the dependencies being run for each directory
+	graph := make(map[string][]string, len(dirs))
+
+	for _, dir := range dirs {
+		for _, dep := range deps[filepath.Clean(dir)] {
+			// find the provided directory matching the dependency
+			i := slices.IndexFunc(dirs, func(d string) bool { return filepath.Clean(d) == dep })
+			if i < 0 {
+				logrus.Debugf("ignoring dependency %s of %s which is not running", dep, dir)
+
+				continue
+			}
+
+			graph[dir] = append(graph[dir], dirs[i])
+		}
+	}
+
+	// order the directories to run after their dependencies
+	dirs, err := sortDirectories(dirs, graph)
+	if err != nil {
+		return err
+	}
+
+	// variable to store the position of each directory
+	pos := make(map[string]int, len(dirs))
+
+	for i, dir := range dirs {
+		pos[dir] = i
+	}
+
+	// variable to store the channel closed when each directory finishes
+	done := make(map[string]chan struct{}, len(dirs))
+
+	for _, dir := range dirs {
+		done[dir] = make(chan struct{})
+	}
+
+	// capture the writer for the output of the directories
+	w := stdout(ctx)
+
+	var (
+		// variable to store the result of each directory
+		results = make([]*result, len(dirs))
+		// semaphore limiting the directories running in parallel
+		sem = make(chan struct{}, d.Concurrency)
+		// mutex to write the output for one directory at a time
+		mu sync.Mutex
+		// variable to store if any directory failed
+		failed atomic.Bool
+		wg     sync.WaitGroup
+	)
+
+	for i, dir := range dirs {
+		sem <- struct{}{}
+
+		// skip the remaining directories after a failure unless configured
+		if failed.Load() && !d.ContinueOnError {
+			results[i] = &result{Name: dir, Skipped: true}
+
+			close(done[dir])
+
+			<-sem
+
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer close(done[dir])
+
+			// wait for the dependencies of the directory to finish
+			for _, dep := range graph[dir] {
+				<-done[dep]
+
+				r := results[pos[dep]]
+
+				// skip the directory when a dependency didn't succeed
+				switch {
+				case r.Err != nil:
+					results[i] = &result{Name: dir, Skipped: true, Reason: fmt.Sprintf("dependency %s failed", dep)}
+
+					return
+				case r.Skipped:
+					results[i] = &result{Name: dir, Skipped: true, Reason: fmt.Sprintf("dependency %s was skipped", dep)}
+
+					return
+				}
+			}
+
+			var err error
+
+			// check if the directories run one at a time
+			if d.Concurrency == 1 {
+				// stream the output for the directory in its own section
+				fmt.Fprintf(w, "\n=== %s ===\n", dir)
+
+				err = fn(ctx, dir)
+			} else {
+				// buffer the output to avoid interleaving directories
+				buf := new(bytes.Buffer)
+
+				err = fn(withOutput(ctx, buf), dir)
+
+				mu.Lock()
+				fmt.Fprintf(w, "\n=== %s ===\n", dir)
+				_, _ = buf.WriteTo(w)
+				flush(w)
+				mu.Unlock()
+			}
+
+			if err != nil {
+				logrus.Errorf("directory %s failed: %v", dir, err)
+
+				failed.Store(true)
+			}
+
+			results[i] = &result{Name: dir, Err: err}
+		}()
+	}
+
+	wg.Wait()
+
+	writeSummary(w, "directory", results)
+	flush(w)
+
+	// variable to store the directories that failed
+	var errs []string
+
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, r.Name)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %s", ErrDirectoriesFailed, strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// Validate verifies the Directories is properly configured.
+func (d *Directories) Validate() error {
+	logrus.Trace("validating directories plugin configuration")
+
+	// verify Concurrency is positive
+	if d.Concurrency < 1 {
+		return fmt.Errorf("invalid concurrency provided: %d", d.Concurrency)
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestTerraform_Directories_Expand(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	for _, dir := range []string{"stacks/app/prod", "stacks/app/dev", "stacks/db/prod", "global"} {
+		err := appFS.MkdirAll(dir, 0755)
+		if err != nil {
+			t.Errorf("unable to create directory %s: %v", dir, err)
+		}
+	}
+
+	err := afero.WriteFile(appFS, "stacks/README.md", []byte("stacks"), 0644)
+	if err != nil {
+		t.Errorf("unable to create file: %v", err)
+	}
+
+	// setup types
+	d := &Directories{
+		Patterns: []string{"global", "stacks/*/prod", "stacks/app/prod", "stacks/*"},
+	}
+
+	want := []string{"global", "stacks/app/prod", "stacks/db/prod", "stacks/app", "stacks/db"}
+
+	got, err := d.Expand()
+	if err != nil {
+		t.Errorf("Expand returned err: %v", err)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("Expand is %v, want %v", got, want)
+	}
+}
+
+func TestTerraform_Directories_Expand_NoMatch(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	err := afero.WriteFile(appFS, "main.tf", []byte(""), 0644)
+	if err != nil {
+		t.Errorf("unable to create file: %v", err)
+	}
+
+	// setup types
+	d := &Directories{
+		Patterns: []string{"*.tf"},
+	}
+
+	_, err = d.Expand()
+	if !errors.Is(err, ErrNoDirectories) {
+		t.Errorf("Expand returned err %v, want %v", err, ErrNoDirectories)
+	}
+}
+
+func TestTerraform_Directories_Run(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name        string
+		directories *Directories
+		want        []string
+		skipped     bool
+	}{
+		{
+			name:        "fail fast",
+			directories: &Directories{Concurrency: 1},
+			want:        []string{"a", "b"},
+			skipped:     true,
+		},
+		{
+			name:        "continue on error",
+			directories: &Directories{Concurrency: 1, ContinueOnError: true},
+			want:        []string{"a", "b", "c"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := new(bytes.Buffer)
+
+			var got []string
+
+			err := test.directories.Run(withOutput(t.Context(), w), []string{"a", "b", "c"}, nil, func(_ context.Context, dir string) error {
+				got = append(got, dir)
+
+				if dir == "b" {
+					return errors.New("exit status 1")
+				}
+
+				return nil
+			})
+			if !errors.Is(err, ErrDirectoriesFailed) {
+				t.Errorf("Run returned err %v, want %v", err, ErrDirectoriesFailed)
+			}
+
+			if !slices.Equal(got, test.want) {
+				t.Errorf("Run ran %v, want %v", got, test.want)
+			}
+
+			if strings.Contains(w.String(), "skipped") != test.skipped {
+				t.Errorf("Run output is %q, want skipped %v", w.String(), test.skipped)
+			}
+		})
+	}
+}
+
+func TestTerraform_Directories_Run_Dependencies(t *testing.T) {
+	// setup types
+	d := &Directories{Concurrency: 2, ContinueOnError: true}
+
+	deps := map[string][]string{
+		"app":     {"network"},
+		"dns":     {"app"},
+		"network": {"global"},
+	}
+
+	w := new(bytes.Buffer)
+
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+
+	err := d.Run(withOutput(t.Context(), w), []string{"dns", "app", "network", "global", "other"}, deps, func(_ context.Context, dir string) error {
+		mu.Lock()
+		got = append(got, dir)
+		mu.Unlock()
+
+		if dir == "app" {
+			return errors.New("exit status 1")
+		}
+
+		return nil
+	})
+	if !errors.Is(err, ErrDirectoriesFailed) {
+		t.Errorf("Run returned err %v, want %v", err, ErrDirectoriesFailed)
+	}
+
+	// verify the dependencies ran before the directories depending on them
+	if i, j := slices.Index(got, "global"), slices.Index(got, "network"); i > j {
+		t.Errorf("Run ran %v, want global before network", got)
+	}
+
+	if slices.Contains(got, "dns") {
+		t.Errorf("Run ran %v, want dns skipped", got)
+	}
+
+	want := "skipped: dependency app failed"
+
+	if !strings.Contains(w.String(), want) {
+		t.Errorf("Run output is %q, want to contain %q", w.String(), want)
+	}
+}
+
+func TestTerraform_Directories_Run_Concurrency(t *testing.T) {
+	// setup types
+	d := &Directories{Concurrency: 2}
+
+	w := new(bytes.Buffer)
+
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+
+	err := d.Run(withOutput(t.Context(), w), []string{"a", "b", "c"}, nil, func(ctx context.Context, dir string) error {
+		mu.Lock()
+		got = append(got, dir)
+		mu.Unlock()
+
+		// write output for the directory to the buffer for its section
+		_, err := stdout(ctx).Write([]byte("output for " + dir + "\n"))
+
+		return err
+	})
+	if err != nil {
+		t.Errorf("Run returned err: %v", err)
+	}
+
+	slices.Sort(got)
+
+	if !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("Run ran %v, want %v", got, []string{"a", "b", "c"})
+	}
+
+	for _, dir := range got {
+		want := "=== " + dir + " ===\noutput for " + dir + "\n"
+
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("Run output is %q, want to contain %q", w.String(), want)
+		}
+	}
+}
+
+func TestTerraform_Directories_Validate(t *testing.T) {
+	// setup types
+	d := &Directories{Concurrency: 0}
+
+	err := d.Validate()
+	if err == nil {
+		t.Errorf("Validate should have returned err")
+	}
+}
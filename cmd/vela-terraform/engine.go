@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// _terraformEngine represents the engine for running HashiCorp Terraform.
+	_terraformEngine = "terraform"
+	// _tofuEngine represents the engine for running OpenTofu.
+	_tofuEngine = "tofu"
+)
+
+// capability represents a flag or feature of the CLI
+// that is only supported by a range of versions.
+type capability string
+
+const (
+	// _chdir represents the global "-chdir" flag.
+	_chdir capability = "-chdir"
+	// _replace represents the "-replace" flag for plan and apply.
+	_replace capability = "-replace"
+	// _moduleDepth represents the "-module-depth" flag for plan.
+	_moduleDepth capability = "-module-depth"
+	// _checkVariables represents the "-check-variables" flag for validate.
+	_checkVariables capability = "-check-variables"
+	// _validateVars represents the "-var" and "-var-file" flags for validate.
+	_validateVars capability = "-var and -var-file for validate"
+	// _getPlugins represents the "-get-plugins" flag for init.
+	_getPlugins capability = "-get-plugins"
+	// _verifyPlugins represents the "-verify-plugins" flag for init.
+	_verifyPlugins capability = "-verify-plugins"
+)
+
+// ErrInvalidEngine defines the error type when the
+// engine provided to the plugin is unsupported.
+var ErrInvalidEngine = errors.New("invalid engine provided")
+
+// Engine represents the CLI used to run the configuration.
+type Engine struct {
+	// name of the engine and its binary i.e. "terraform" or "tofu"
+	Name string
+	// name of the file used to pin the version i.e. ".terraform-version"
+	VersionFile string
+	// version constraints supporting each capability of the engine ending in "-0"
+	// to match prereleases, capabilities missing from the table are unsupported
+	Capabilities map[capability]string
+}
+
+// engines represents the supported engines and the
+// version constraints for the capabilities of each.
+var engines = map[string]*Engine{
+	_terraformEngine: {
+		Name:        _terraformEngine,
+		VersionFile: ".terraform-version",
+		Capabilities: map[capability]string{
+			_chdir:          ">= 0.14.0-0",
+			_replace:        ">= 0.15.2-0",
+			_moduleDepth:    "< 0.12.0-0",
+			_checkVariables: "< 0.12.0-0",
+			_validateVars:   "< 0.12.0-0",
+			_getPlugins:     "< 0.15.0-0",
+			_verifyPlugins:  "< 0.15.0-0",
+		},
+	},
+	_tofuEngine: {
+		Name:        _tofuEngine,
+		VersionFile: ".opentofu-version",
+		Capabilities: map[capability]string{
+			_chdir:   ">= 1.6.0-0",
+			_replace: ">= 1.6.0-0",
+		},
+	},
+}
+
+// engine represents the engine selected for the plugin.
+var engine = engines[_terraformEngine]
+
+// selectEngine is a helper function to select
+// the engine used to run the configuration.
+func selectEngine(name string) error {
+	e, ok := engines[name]
+	if !ok {
+		return fmt.Errorf("%w: %s (Valid engines: %s, %s)", ErrInvalidEngine, name, _terraformEngine, _tofuEngine)
+	}
+
+	logrus.Debugf("using %s engine", e.Name)
+
+	engine = e
+
+	return nil
+}
+
+// Supports returns true if the version of the engine supports the capability.
+func (e *Engine) Supports(c capability, v *semver.Version) bool {
+	// assume the capability is supported when the version is unknown
+	if v == nil {
+		return true
+	}
+
+	constraint, ok := e.Capabilities[c]
+	if !ok {
+		return false
+	}
+
+	return mustConstraint(constraint).Check(v)
+}
+
+// mustConstraint is a helper function to parse the
+// version constraints from the capability table.
+func mustConstraint(c string) *semver.Constraints {
+	constraints, err := semver.NewConstraint(c)
+	if err != nil {
+		panic(err)
+	}
+
+	return constraints
+}
+
+// supported is a helper function to check if the engine version supports
+// the capability and warn that the flag is dropped when it doesn't.
+func supported(c capability, v *semver.Version) bool {
+	// check if the capability is supported
+	if engine.Supports(c, v) {
+		return true
+	}
+
+	logrus.Warnf("%s %s does not support %s, dropping flag", engine.Name, v, c)
+
+	return false
+}
+
+// unsupported is a helper function to create the error
+// for a flag that can't be dropped from the command.
+func unsupported(c capability, v *semver.Version) error {
+	return fmt.Errorf("%w: %s %s does not support %s", ErrUnsupportedFlag, engine.Name, v, c)
+}
+
+// SupportsChdir returns true if the engine
+// version supports the global -chdir flag.
+func SupportsChdir(v *semver.Version) bool {
+	return engine.Supports(_chdir, v)
+}
+
+// SupportsReplace returns true if the engine
+// version supports the -replace flag.
+func SupportsReplace(v *semver.Version) bool {
+	return engine.Supports(_replace, v)
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func TestTerraform_Engine_Supports(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		engine     string
+		capability capability
+		version    string
+		want       bool
+	}{
+		{engine: _terraformEngine, capability: _chdir, version: "0.13.7", want: false},
+		{engine: _terraformEngine, capability: _chdir, version: "0.14.0", want: true},
+		{engine: _terraformEngine, capability: _chdir, version: "1.7.4", want: true},
+		{engine: _terraformEngine, capability: _replace, version: "0.15.1", want: false},
+		{engine: _terraformEngine, capability: _replace, version: "0.15.2", want: true},
+		{engine: _terraformEngine, capability: _chdir, version: "1.8.0-rc1", want: true},
+		{engine: _terraformEngine, capability: _replace, version: "1.8.0-beta1", want: true},
+		{engine: _terraformEngine, capability: _moduleDepth, version: "0.12.0-beta1", want: false},
+		{engine: _terraformEngine, capability: _getPlugins, version: "0.14.0-rc1", want: true},
+		{engine: _tofuEngine, capability: _chdir, version: "1.6.0", want: true},
+		{engine: _terraformEngine, capability: _moduleDepth, version: "0.11.14", want: true},
+		{engine: _terraformEngine, capability: _moduleDepth, version: "0.12.0", want: false},
+		{engine: _terraformEngine, capability: _checkVariables, version: "0.12.0", want: false},
+		{engine: _terraformEngine, capability: _validateVars, version: "0.11.14", want: true},
+		{engine: _terraformEngine, capability: _getPlugins, version: "0.14.11", want: true},
+		{engine: _terraformEngine, capability: _verifyPlugins, version: "0.15.0", want: false},
+		{engine: _tofuEngine, capability: _chdir, version: "1.6.0", want: true},
+		{engine: _tofuEngine, capability: _replace, version: "1.6.0-alpha1", want: true},
+		{engine: _tofuEngine, capability: _getPlugins, version: "1.6.0", want: false},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := engines[test.engine].Supports(test.capability, semver.MustParse(test.version))
+		if got != test.want {
+			t.Errorf("Supports %s for %s %s is %v, want %v", test.capability, test.engine, test.version, got, test.want)
+		}
+	}
+}
+
+func TestTerraform_Engine_Supports_NoVersion(t *testing.T) {
+	if !engines[_terraformEngine].Supports(_chdir, nil) {
+		t.Errorf("Supports should be true when the version is unknown")
+	}
+}
+
+func TestTerraform_selectEngine(t *testing.T) {
+	t.Cleanup(func() { engine = engines[_terraformEngine] })
+
+	err := selectEngine(_tofuEngine)
+	if err != nil {
+		t.Errorf("selectEngine returned err: %v", err)
+	}
+
+	if engine.Name != _tofuEngine {
+		t.Errorf("engine is %v, want %v", engine.Name, _tofuEngine)
+	}
+
+	// tofu supports chdir for all releases
+	if !SupportsChdir(semver.MustParse("1.6.0")) {
+		t.Errorf("SupportsChdir should be true for tofu 1.6.0")
+	}
+
+	err = selectEngine("foobar")
+	if !errors.Is(err, ErrInvalidEngine) {
+		t.Errorf("selectEngine returned err %v, want %v", err, ErrInvalidEngine)
+	}
+}
+
+func TestTerraform_supported(t *testing.T) {
+	if supported(_moduleDepth, semver.MustParse("1.0.0")) {
+		t.Errorf("supported should be false for -module-depth on terraform 1.0.0")
+	}
+
+	if !supported(_moduleDepth, semver.MustParse("0.11.0")) {
+		t.Errorf("supported should be true for -module-depth on terraform 0.11.0")
+	}
+
+	err := unsupported(_replace, semver.MustParse("0.15.0"))
+	if !errors.Is(err, ErrUnsupportedFlag) {
+		t.Errorf("unsupported returned err %v, want %v", err, ErrUnsupportedFlag)
+	}
+}
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// _varPrefix represents the prefix of the parameters
+	// mapped to terraform variables i.e. "PARAMETER_TFVAR_DB_PASSWORD".
+	_varPrefix = "PARAMETER_TFVAR_"
+	// _varSecretsDir represents the directory of the secret
+	// files mapped to terraform variables i.e. ".../vars/db_password".
+	_varSecretsDir = "/vela/secrets/terraform/vars"
+)
+
+// Environment represents the plugin configuration for
+// mapping the environment to terraform variables.
+type Environment struct {
+	// raw input of the map of terraform variables to environment variables
+	RawVarEnvMap string
+	// if set, map any prefixed parameters and secret files to terraform variables
+	VarPrefix bool
+	// terraform variables mapped to environment variables parsed from the raw input
+	varEnvMap map[string]string
+}
+
+// Export sets the TF_VAR_* environment variables for
+// the terraform variables mapped from the environment.
+func (e *Environment) Export() error {
+	logrus.Trace("exporting terraform variables from environment")
+
+	// lower case the existing terraform variables
+	err := env()
+	if err != nil {
+		return err
+	}
+
+	// check if prefixed parameters and secret files should be mapped
+	if e.VarPrefix {
+		vars, err := prefixedVars()
+		if err != nil {
+			return err
+		}
+
+		for name, value := range vars {
+			err = setVar(name, value)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for name, key := range e.varEnvMap {
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			logrus.Warnf("environment variable %s for terraform variable %s is not set", key, name)
+
+			continue
+		}
+
+		err = setVar(name, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Unmarshal parses the raw input of the variable env map.
+func (e *Environment) Unmarshal() error {
+	logrus.Trace("unmarshalling variable env map")
+
+	e.varEnvMap = nil
+
+	// check if a map was passed
+	if len(e.RawVarEnvMap) == 0 {
+		return nil
+	}
+
+	// JSON is parsed as a subset of YAML
+	err := yaml.Unmarshal([]byte(e.RawVarEnvMap), &e.varEnvMap)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal var env map: %w", err)
+	}
+
+	return nil
+}
+
+// prefixedVars is a helper function to return the terraform
+// variables from the prefixed parameters and secret files.
+func prefixedVars() (map[string]string, error) {
+	vars := make(map[string]string)
+
+	for _, e := range os.Environ() {
+		// split on value
+		pair := strings.SplitN(e, "=", 2)
+
+		// match on PARAMETER_TFVAR_*
+		name, ok := strings.CutPrefix(pair[0], _varPrefix)
+		if !ok || len(name) == 0 {
+			continue
+		}
+
+		// lower case the terraform variable
+		//   to accommodate cicd injection capitalization
+		vars[strings.ToLower(name)] = pair[1]
+	}
+
+	secrets, err := secretVars()
+	if err != nil {
+		return nil, err
+	}
+
+	// the secret files take precedence over the prefixed parameters
+	maps.Copy(vars, secrets)
+
+	return vars, nil
+}
+
+// secretVars is a helper function to return the terraform
+// variables from the secret files mounted by Vela.
+func secretVars() (map[string]string, error) {
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	// check if any secret files were mounted
+	ok, err := a.DirExists(_varSecretsDir)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	files, err := a.ReadDir(_varSecretsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string)
+
+	for _, f := range files {
+		// skip any directories
+		if f.IsDir() {
+			continue
+		}
+
+		b, err := a.ReadFile(filepath.Join(_varSecretsDir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		vars[f.Name()] = strings.TrimRight(string(b), "\r\n")
+	}
+
+	return vars, nil
+}
+
+// setVar is a helper function to set the
+// environment variable for the terraform variable.
+func setVar(name, value string) error {
+	logrus.Debugf("setting terraform variable %s from environment", name)
+
+	return os.Setenv(fmt.Sprintf("TF_VAR_%s", name), value)
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestTerraform_Environment_Export(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	err := afero.WriteFile(appFS, _varSecretsDir+"/api_token", []byte("token\n"), 0600)
+	if err != nil {
+		t.Errorf("unable to create file: %v", err)
+	}
+
+	// setup env
+	t.Setenv("DB_PASSWORD", "hunter2")
+	t.Setenv("PARAMETER_TFVAR_REGION", "us-east-1")
+
+	for _, name := range []string{"db_password", "region", "api_token", "missing"} {
+		t.Setenv("TF_VAR_"+name, "")
+	}
+
+	// setup types
+	e := &Environment{
+		RawVarEnvMap: `{"db_password": "DB_PASSWORD", "missing": "NOT_SET"}`,
+		VarPrefix:    true,
+	}
+
+	err = e.Unmarshal()
+	if err != nil {
+		t.Errorf("Unmarshal returned err: %v", err)
+	}
+
+	err = e.Export()
+	if err != nil {
+		t.Errorf("Export returned err: %v", err)
+	}
+
+	want := map[string]string{
+		"TF_VAR_db_password": "hunter2",
+		"TF_VAR_region":      "us-east-1",
+		"TF_VAR_api_token":   "token",
+		"TF_VAR_missing":     "",
+	}
+
+	for key, value := range want {
+		got := os.Getenv(key)
+		if got != value {
+			t.Errorf("os.Getenv for %s is %v, want %v", key, got, value)
+		}
+	}
+}
+
+func TestTerraform_Environment_Export_NoPrefix(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	// setup env
+	t.Setenv("PARAMETER_TFVAR_REGION", "us-east-1")
+	t.Setenv("TF_VAR_region", "")
+
+	// setup types
+	e := &Environment{}
+
+	err := e.Unmarshal()
+	if err != nil {
+		t.Errorf("Unmarshal returned err: %v", err)
+	}
+
+	err = e.Export()
+	if err != nil {
+		t.Errorf("Export returned err: %v", err)
+	}
+
+	got := os.Getenv("TF_VAR_region")
+	if len(got) > 0 {
+		t.Errorf("os.Getenv is %v, want empty", got)
+	}
+}
+
+func TestTerraform_Environment_Unmarshal_Invalid(t *testing.T) {
+	// setup types
+	e := &Environment{RawVarEnvMap: "[ DB_PASSWORD ]"}
+
+	err := e.Unmarshal()
+	if err == nil {
+		t.Errorf("Unmarshal should have returned err")
+	}
+}
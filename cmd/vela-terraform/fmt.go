@@ -77,7 +77,7 @@ func (f *FMT) Command(ctx context.Context) *exec.Cmd {
 	globalFlags = append(globalFlags, fmtAction)
 
 	//nolint:gosec // ignore G204
-	return exec.CommandContext(ctx, _terraform, append(globalFlags, flags...)...)
+	return exec.CommandContext(ctx, terraformBin, append(globalFlags, flags...)...)
 }
 
 // Exec formats and runs the commands for formatting Terraform files.
@@ -88,7 +88,7 @@ func (f *FMT) Exec(ctx context.Context) error {
 	cmd := f.Command(ctx)
 
 	// run the fmt command for the file
-	err := execCmd(cmd)
+	err := execCmd(ctx, cmd)
 	if err != nil {
 		return err
 	}
@@ -26,7 +26,7 @@ func TestTerraform_FMT_Command(t *testing.T) {
 	//nolint:gosec // ignore G204
 	want := exec.CommandContext(
 		t.Context(),
-		_terraform,
+		terraformBin,
 		fmt.Sprintf("-chdir=%s", f.Directory),
 		fmtAction,
 		fmt.Sprintf("-list=%t", f.List),
@@ -60,7 +60,7 @@ func TestTerraform_FMT_Command_tf13(t *testing.T) {
 	//nolint:gosec // ignore G204
 	want := exec.CommandContext(
 		t.Context(),
-		_terraform,
+		terraformBin,
 		fmtAction,
 		fmt.Sprintf("-list=%t", f.List),
 		fmt.Sprintf("-write=%t", f.Write),
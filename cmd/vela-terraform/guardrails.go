@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrGuardrail defines the error type when a
+// plan exceeds the configured change thresholds.
+var ErrGuardrail = errors.New("plan exceeds guardrails")
+
+// Guardrails represents the plugin configuration for change thresholds.
+type Guardrails struct {
+	// maximum number of resources to destroy or replace, negative values disable the limit
+	MaxDestroy int
+	// maximum number of resources to add, change, destroy or replace, negative values disable the limit
+	MaxChanges int
+	// resource types that may not be destroyed or replaced i.e. "aws_db_instance"
+	DenyDestroyTypes []string
+}
+
+// Enabled returns true if any guardrails are configured.
+func (g *Guardrails) Enabled() bool {
+	// check if guardrails were provided
+	if g == nil {
+		return false
+	}
+
+	return g.MaxDestroy >= 0 || g.MaxChanges >= 0 || len(g.DenyDestroyTypes) > 0
+}
+
+// Check verifies the plan doesn't exceed the configured guardrails.
+func (g *Guardrails) Check(plan *planJSON) error {
+	logrus.Trace("checking plan against guardrails")
+
+	// check if guardrails are configured
+	if !g.Enabled() {
+		return nil
+	}
+
+	changes := plan.Changes()
+
+	// variable to store each guardrail the plan exceeds
+	var violations []string
+
+	// capture the resources that will be destroyed or replaced
+	destroyed := slices.Concat(changes[_destroyChange], changes[_replaceChange])
+
+	// check if MaxDestroy is exceeded
+	if g.MaxDestroy >= 0 && len(destroyed) > g.MaxDestroy {
+		violations = append(violations, fmt.Sprintf(
+			"%d resources to destroy exceeds max_destroy of %d: %s",
+			len(destroyed), g.MaxDestroy, addresses(destroyed),
+		))
+	}
+
+	// capture all resources that will be modified
+	modified := slices.Concat(destroyed, changes[_addChange], changes[_updateChange])
+
+	// check if MaxChanges is exceeded
+	if g.MaxChanges >= 0 && len(modified) > g.MaxChanges {
+		violations = append(violations, fmt.Sprintf(
+			"%d resources to change exceeds max_changes of %d: %s",
+			len(modified), g.MaxChanges, addresses(modified),
+		))
+	}
+
+	// check if any denied resource types are destroyed
+	for _, t := range g.DenyDestroyTypes {
+		denied := slices.DeleteFunc(slices.Clone(destroyed), func(r *resourceChange) bool {
+			return r.Type != t
+		})
+
+		if len(denied) > 0 {
+			violations = append(violations, fmt.Sprintf(
+				"%s resources may not be destroyed: %s",
+				t, addresses(denied),
+			))
+		}
+	}
+
+	// check if the plan exceeds any guardrails
+	if len(violations) > 0 {
+		return fmt.Errorf("%w:\n  %s", ErrGuardrail, strings.Join(violations, "\n  "))
+	}
+
+	return nil
+}
+
+// addresses is a helper function to join
+// the addresses of the provided resources.
+func addresses(resources []*resourceChange) string {
+	addrs := make([]string, 0, len(resources))
+
+	for _, r := range resources {
+		addrs = append(addrs, r.Address)
+	}
+
+	return strings.Join(addrs, ", ")
+}
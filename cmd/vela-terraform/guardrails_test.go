@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTerraform_Guardrails_Enabled(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		guardrails *Guardrails
+		want       bool
+	}{
+		{
+			guardrails: nil,
+			want:       false,
+		},
+		{
+			guardrails: &Guardrails{MaxDestroy: -1, MaxChanges: -1},
+			want:       false,
+		},
+		{
+			guardrails: &Guardrails{MaxDestroy: 0, MaxChanges: -1},
+			want:       true,
+		},
+		{
+			guardrails: &Guardrails{MaxDestroy: -1, MaxChanges: 10},
+			want:       true,
+		},
+		{
+			guardrails: &Guardrails{MaxDestroy: -1, MaxChanges: -1, DenyDestroyTypes: []string{"aws_db_instance"}},
+			want:       true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := test.guardrails.Enabled()
+		if got != test.want {
+			t.Errorf("Enabled for %v is %v, want %v", test.guardrails, got, test.want)
+		}
+	}
+}
+
+func TestTerraform_Guardrails_Check(t *testing.T) {
+	// setup types
+	plan := new(planJSON)
+
+	err := json.Unmarshal([]byte(testPlan), plan)
+	if err != nil {
+		t.Errorf("Unable to unmarshal plan: %v", err)
+	}
+
+	// setup tests
+	tests := []struct {
+		guardrails *Guardrails
+		want       []string
+	}{
+		{
+			guardrails: nil,
+		},
+		{
+			guardrails: &Guardrails{MaxDestroy: 2, MaxChanges: 5},
+		},
+		{
+			guardrails: &Guardrails{MaxDestroy: 1, MaxChanges: -1},
+			want:       []string{"2 resources to destroy exceeds max_destroy of 1: aws_instance.old, module.db.aws_db_instance.main"},
+		},
+		{
+			guardrails: &Guardrails{MaxDestroy: -1, MaxChanges: 4},
+			want:       []string{"5 resources to change exceeds max_changes of 4"},
+		},
+		{
+			guardrails: &Guardrails{MaxDestroy: -1, MaxChanges: -1, DenyDestroyTypes: []string{"aws_db_instance", "aws_s3_bucket"}},
+			want:       []string{"aws_db_instance resources may not be destroyed: module.db.aws_db_instance.main"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		err := test.guardrails.Check(plan)
+
+		if len(test.want) == 0 {
+			if err != nil {
+				t.Errorf("Check returned err: %v", err)
+			}
+
+			continue
+		}
+
+		if !errors.Is(err, ErrGuardrail) {
+			t.Errorf("Check returned err %v, want %v", err, ErrGuardrail)
+
+			continue
+		}
+
+		for _, want := range test.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Check returned err %q, want to contain %q", err, want)
+			}
+		}
+
+		if strings.Contains(err.Error(), "aws_s3_bucket resources") {
+			t.Errorf("Check returned err %q, should not deny created resources", err)
+		}
+	}
+}
@@ -10,20 +10,35 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 )
 
 const initAction = "init"
 
+const (
+	// _dataDir represents the directory terraform
+	// uses to store the initialized working data.
+	_dataDir = ".terraform"
+	// _lockFile represents the dependency lock file
+	// created by terraform during initialization.
+	_lockFile = ".terraform.lock.hcl"
+)
+
 type (
 	// Init represents the plugin configuration for init information.
 	Init struct {
+		// path to an archive of the initialized working directory
+		Archive string
 		// terraform file or directory to init
 		Directory string
 		// init for initialize a new or existing Terraform working directory
 		InitOptions *InitOptions
 		// raw input of init options provided for plugin
 		RawInit string
+		// version of terraform used to format the command
+		Version *semver.Version
 	}
 
 	// InitOptions represents the plugin configuration for options for init.
@@ -73,9 +88,9 @@ func (i *Init) Command(ctx context.Context) *exec.Cmd {
 	// variable to store flags for command
 	var flags []string
 
-	// check if Directory is provided
-	if i.Directory != "." {
-		globalFlags = append(flags, fmt.Sprintf("-chdir=%s", i.Directory))
+	// check if Directory is provided and terraform version supports chdir
+	if i.Directory != "." && SupportsChdir(i.Version) {
+		globalFlags = append(globalFlags, fmt.Sprintf("-chdir=%s", i.Directory))
 	}
 
 	// check if Backend is provided
@@ -110,8 +125,8 @@ func (i *Init) Command(ctx context.Context) *exec.Cmd {
 		flags = append(flags, "-get=true")
 	}
 
-	// check if GetPlugins is provided
-	if i.InitOptions.GetPlugins {
+	// check if GetPlugins is provided and supported by the version
+	if i.InitOptions.GetPlugins && supported(_getPlugins, i.Version) {
 		// add flag for GetPlugins from provided init command
 		flags = append(flags, "-get-plugins=true")
 	}
@@ -160,16 +175,21 @@ func (i *Init) Command(ctx context.Context) *exec.Cmd {
 		flags = append(flags, "-upgrade")
 	}
 
-	// check if VerifyPlugins is provided
-	if i.InitOptions.VerifyPlugins {
+	// check if VerifyPlugins is provided and supported by the version
+	if i.InitOptions.VerifyPlugins && supported(_verifyPlugins, i.Version) {
 		// add flag for VerifyPlugins from provided init command
 		flags = append(flags, "-verify-plugins=true")
 	}
 
+	// check if Directory is provided and terraform version doesn't support chdir
+	if i.Directory != "." && !SupportsChdir(i.Version) {
+		flags = append(flags, i.Directory)
+	}
+
 	globalFlags = append(globalFlags, initAction)
 
 	//nolint:gosec // ignore G204
-	return exec.CommandContext(ctx, _terraform, append(globalFlags, flags...)...)
+	return exec.CommandContext(ctx, terraformBin, append(globalFlags, flags...)...)
 }
 
 // Exec formats and runs the commands for initing Terraform.
@@ -180,7 +200,7 @@ func (i *Init) Exec(ctx context.Context) error {
 	cmd := i.Command(ctx)
 
 	// run the init command for the file
-	err := execCmd(cmd)
+	err := execCmd(ctx, cmd)
 	if err != nil {
 		return err
 	}
@@ -188,6 +208,60 @@ func (i *Init) Exec(ctx context.Context) error {
 	return nil
 }
 
+// Package creates an archive of the initialized working
+// directory so it can be reused by later pipeline steps.
+func (i *Init) Package() error {
+	logrus.Trace("packaging initialized working directory")
+
+	// check if Archive is provided
+	if len(i.Archive) == 0 {
+		logrus.Debug("no init archive provided, skipping packaging")
+
+		return nil
+	}
+
+	logrus.Infof("packaging %s and %s to %s", _dataDir, _lockFile, i.Archive)
+
+	return archive(i.Archive, i.Directory, _dataDir, _lockFile)
+}
+
+// Restore extracts an archive of a previously initialized
+// working directory and reports if it was restored.
+func (i *Init) Restore() (bool, error) {
+	logrus.Trace("restoring initialized working directory")
+
+	// check if Archive is provided
+	if len(i.Archive) == 0 {
+		return false, nil
+	}
+
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	// check if the archive was created by a previous step
+	ok, err := a.Exists(i.Archive)
+	if err != nil {
+		return false, err
+	}
+
+	if !ok {
+		logrus.Debugf("init archive %s does not exist, skipping restore", i.Archive)
+
+		return false, nil
+	}
+
+	logrus.Infof("restoring initialized working directory from %s", i.Archive)
+
+	err = extract(i.Archive, i.Directory)
+	if err != nil {
+		return false, fmt.Errorf("failed to restore init archive: %w", err)
+	}
+
+	return true, nil
+}
+
 // Validate verifies the Init is properly configured.
 func (i *Init) Validate() error {
 	logrus.Trace("validating plan plugin configuration")
@@ -199,6 +273,22 @@ func (i *Init) Validate() error {
 	return nil
 }
 
+// BackendKey returns the key for the state provided
+// by the backend configuration if one exists.
+func (o *InitOptions) BackendKey() string {
+	for _, config := range o.BackendConfigs {
+		// split the backend configuration on key and value
+		key, value, ok := strings.Cut(config, "=")
+
+		// check if the configuration provides the state key
+		if ok && strings.TrimSpace(key) == "key" {
+			return strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+
+	return ""
+}
+
 // Unmarshal captures the provided properties and
 // serializes them into their expected form.
 func (i *Init) Unmarshal() error {
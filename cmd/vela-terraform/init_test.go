@@ -8,6 +8,9 @@ import (
 	"slices"
 	"testing"
 	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/spf13/afero"
 )
 
 func TestTerraform_Init_Command(t *testing.T) {
@@ -35,7 +38,7 @@ func TestTerraform_Init_Command(t *testing.T) {
 	//nolint:gosec // ignore G204
 	want := exec.CommandContext(
 		t.Context(),
-		_terraform,
+		terraformBin,
 		fmt.Sprintf("-chdir=%s", i.Directory),
 		initAction,
 		"-backend=true",
@@ -66,6 +69,42 @@ func TestTerraform_Init_Command(t *testing.T) {
 	}
 }
 
+func TestTerraform_Init_Command_tf13(t *testing.T) {
+	v, _ := semver.NewVersion("0.13.0")
+	// setup types
+	i := &Init{
+		Directory: "foobar/",
+		InitOptions: &InitOptions{
+			Backend:       true,
+			GetPlugins:    true,
+			NoColor:       true,
+			VerifyPlugins: true,
+		},
+		Version: v,
+	}
+
+	//nolint:gosec // ignore G204
+	want := exec.CommandContext(
+		t.Context(),
+		terraformBin,
+		initAction,
+		"-backend=true",
+		"-get-plugins=true",
+		"-no-color",
+		"-verify-plugins=true",
+		fmt.Sprint(i.Directory),
+	)
+
+	got := i.Command(t.Context())
+	if got.Path != want.Path {
+		t.Errorf("Command path is %v, want %v", got.Path, want.Path)
+	}
+
+	if !slices.Equal(got.Args, want.Args) {
+		t.Errorf("Command args is %v, want %v", got.Args, want.Args)
+	}
+}
+
 func TestTerraform_Init_Exec_Error(t *testing.T) {
 	// setup types
 	i := &Init{
@@ -103,3 +142,115 @@ func TestTerraform_Init_Validate(t *testing.T) {
 		}
 	}
 }
+
+func TestTerraform_Init_Package(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	err := a.WriteFile("foobar/.terraform.lock.hcl", []byte("# lock"), 0644)
+	if err != nil {
+		t.Errorf("Unable to write file: %v", err)
+	}
+
+	// setup types
+	i := &Init{
+		Archive:   "init.tar.gz",
+		Directory: "foobar",
+	}
+
+	err = i.Package()
+	if err != nil {
+		t.Errorf("Package returned err: %v", err)
+	}
+
+	ok, err := a.Exists(i.Archive)
+	if err != nil || !ok {
+		t.Errorf("Package should have created %s", i.Archive)
+	}
+
+	// restore into a clean directory
+	err = a.RemoveAll("foobar")
+	if err != nil {
+		t.Errorf("Unable to remove directory: %v", err)
+	}
+
+	restored, err := i.Restore()
+	if err != nil {
+		t.Errorf("Restore returned err: %v", err)
+	}
+
+	if !restored {
+		t.Errorf("Restore should have restored %s", i.Archive)
+	}
+
+	ok, err = a.Exists("foobar/.terraform.lock.hcl")
+	if err != nil || !ok {
+		t.Errorf("Restore should have created %s", "foobar/.terraform.lock.hcl")
+	}
+}
+
+func TestTerraform_Init_Restore_NoArchive(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	// setup types
+	tests := []struct {
+		init *Init
+	}{
+		{
+			init: &Init{Directory: "foobar/"},
+		},
+		{
+			init: &Init{Archive: "init.tar.gz", Directory: "foobar/"},
+		},
+	}
+
+	// run test
+	for _, test := range tests {
+		restored, err := test.init.Restore()
+		if err != nil {
+			t.Errorf("Restore returned err: %v", err)
+		}
+
+		if restored {
+			t.Errorf("Restore should not have restored %s", test.init.Archive)
+		}
+	}
+}
+
+func TestTerraform_InitOptions_BackendKey(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		options *InitOptions
+		want    string
+	}{
+		{
+			options: &InitOptions{},
+			want:    "",
+		},
+		{
+			options: &InitOptions{BackendConfigs: []string{"backend.hcl"}},
+			want:    "",
+		},
+		{
+			options: &InitOptions{BackendConfigs: []string{"bucket=state", "key=prod/network.tfstate"}},
+			want:    "prod/network.tfstate",
+		},
+		{
+			options: &InitOptions{BackendConfigs: []string{`key = "prod/network.tfstate"`}},
+			want:    "prod/network.tfstate",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := test.options.BackendKey()
+		if got != test.want {
+			t.Errorf("BackendKey is %v, want %v", got, test.want)
+		}
+	}
+}
@@ -67,6 +67,26 @@ func main() {
 					cli.File("/vela/secrets/terraform/backup"),
 				),
 			},
+			&cli.StringSliceFlag{
+				Name:  "confirm_destroy",
+				Usage: "confirmations matching the workspace, backend key or each directory to destroy",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_CONFIRM_DESTROY"),
+					cli.EnvVar("TERRAFORM_CONFIRM_DESTROY"),
+					cli.File("/vela/parameters/terraform/confirm_destroy"),
+					cli.File("/vela/secrets/terraform/confirm_destroy"),
+				),
+			},
+			&cli.StringSliceFlag{
+				Name:  "deny_destroy_types",
+				Usage: "resource types that may not be destroyed or replaced by a plan",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_DENY_DESTROY_TYPES"),
+					cli.EnvVar("TERRAFORM_DENY_DESTROY_TYPES"),
+					cli.File("/vela/parameters/terraform/deny_destroy_types"),
+					cli.File("/vela/secrets/terraform/deny_destroy_types"),
+				),
+			},
 			&cli.StringFlag{
 				Name:  "directory",
 				Value: ".",
@@ -78,6 +98,17 @@ func main() {
 					cli.File("/vela/secrets/terraform/directory"),
 				),
 			},
+			&cli.StringFlag{
+				Name:  "engine",
+				Value: _terraformEngine,
+				Usage: "engine used to run the configuration - options: (terraform|tofu)",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_ENGINE"),
+					cli.EnvVar("TERRAFORM_ENGINE"),
+					cli.File("/vela/parameters/terraform/engine"),
+					cli.File("/vela/secrets/terraform/engine"),
+				),
+			},
 			&cli.BoolFlag{
 				Name:  "lock",
 				Usage: "lock the state file when locking is supported",
@@ -109,6 +140,28 @@ func main() {
 					cli.File("/vela/secrets/terraform/log_level"),
 				),
 			},
+			&cli.IntFlag{
+				Name:  "max_changes",
+				Value: -1,
+				Usage: "maximum number of resources a plan may modify (negative disables the limit)",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_MAX_CHANGES"),
+					cli.EnvVar("TERRAFORM_MAX_CHANGES"),
+					cli.File("/vela/parameters/terraform/max_changes"),
+					cli.File("/vela/secrets/terraform/max_changes"),
+				),
+			},
+			&cli.IntFlag{
+				Name:  "max_destroy",
+				Value: -1,
+				Usage: "maximum number of resources a plan may destroy or replace (negative disables the limit)",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_MAX_DESTROY"),
+					cli.EnvVar("TERRAFORM_MAX_DESTROY"),
+					cli.File("/vela/parameters/terraform/max_destroy"),
+					cli.File("/vela/secrets/terraform/max_destroy"),
+				),
+			},
 			&cli.BoolFlag{
 				Name:  "no_color",
 				Usage: "disables colors in output",
@@ -129,6 +182,26 @@ func main() {
 					cli.File("/vela/secrets/terraform/parallelism"),
 				),
 			},
+			&cli.StringFlag{
+				Name:  "plan_file",
+				Usage: "path to a saved plan file to apply",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_PLAN_FILE"),
+					cli.EnvVar("TERRAFORM_PLAN_FILE"),
+					cli.File("/vela/parameters/terraform/plan_file"),
+					cli.File("/vela/secrets/terraform/plan_file"),
+				),
+			},
+			&cli.StringSliceFlag{
+				Name:  "protected_patterns",
+				Usage: "branch or deployment patterns where destroy is refused",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_PROTECTED_PATTERNS"),
+					cli.EnvVar("TERRAFORM_PROTECTED_PATTERNS"),
+					cli.File("/vela/parameters/terraform/protected_patterns"),
+					cli.File("/vela/secrets/terraform/protected_patterns"),
+				),
+			},
 			&cli.BoolFlag{
 				Name:  "refresh",
 				Usage: "update state prior to checking for differences",
@@ -139,6 +212,16 @@ func main() {
 					cli.File("/vela/secrets/terraform/refresh"),
 				),
 			},
+			&cli.StringSliceFlag{
+				Name:  "replace",
+				Usage: "resources to replace (requires terraform 0.15.2 or later)",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_REPLACE"),
+					cli.EnvVar("TERRAFORM_REPLACE"),
+					cli.File("/vela/parameters/terraform/replace"),
+					cli.File("/vela/secrets/terraform/replace"),
+				),
+			},
 			&cli.StringFlag{
 				Name:  "state",
 				Usage: "path to read and save state",
@@ -159,12 +242,17 @@ func main() {
 					cli.File("/vela/secrets/terraform/state_out"),
 				),
 			},
-			&cli.StringFlag{
-				Name:  "target",
-				Usage: "resource to target",
+			&cli.StringSliceFlag{
+				Name:    "targets",
+				Aliases: []string{"target"},
+				Usage:   "resources to target",
 				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_TARGETS"),
+					cli.EnvVar("TERRAFORM_TARGETS"),
 					cli.EnvVar("PARAMETER_TARGET"),
 					cli.EnvVar("TERRAFORM_TARGET"),
+					cli.File("/vela/parameters/terraform/targets"),
+					cli.File("/vela/secrets/terraform/targets"),
 					cli.File("/vela/parameters/terraform/target"),
 					cli.File("/vela/secrets/terraform/target"),
 				),
@@ -179,6 +267,36 @@ func main() {
 					cli.File("/vela/secrets/terraform/vars"),
 				),
 			},
+			&cli.StringFlag{
+				Name:  "variables",
+				Usage: "a YAML or JSON map of variables to pass to Terraform",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_VARIABLES"),
+					cli.EnvVar("TERRAFORM_VARIABLES"),
+					cli.File("/vela/parameters/terraform/variables"),
+					cli.File("/vela/secrets/terraform/variables"),
+				),
+			},
+			&cli.StringFlag{
+				Name:  "var_env_map",
+				Usage: "a YAML or JSON map of Terraform variables to the environment variables to set them from",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_VAR_ENV_MAP"),
+					cli.EnvVar("TERRAFORM_VAR_ENV_MAP"),
+					cli.File("/vela/parameters/terraform/var_env_map"),
+					cli.File("/vela/secrets/terraform/var_env_map"),
+				),
+			},
+			&cli.BoolFlag{
+				Name:  "var_prefix",
+				Usage: "set Terraform variables from PARAMETER_TFVAR_* variables and /vela/secrets/terraform/vars/* files",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_VAR_PREFIX"),
+					cli.EnvVar("TERRAFORM_VAR_PREFIX"),
+					cli.File("/vela/parameters/terraform/var_prefix"),
+					cli.File("/vela/secrets/terraform/var_prefix"),
+				),
+			},
 			&cli.StringSliceFlag{
 				Name:  "var_files",
 				Usage: "a list of var files to use",
@@ -191,28 +309,183 @@ func main() {
 			},
 			&cli.StringFlag{
 				Name:  "terraform.version",
-				Usage: "set terraform version for plugin",
+				Usage: "set terraform version, version constraints or auto for plugin",
 				Sources: cli.NewValueSourceChain(
 					cli.EnvVar("PARAMETER_VERSION"),
 					cli.EnvVar("TERRAFORM_VERSION"),
-					cli.EnvVar("PLUGIN_TERRAFORM_VERSION"),
 					cli.File("/vela/parameters/terraform/version"),
 					cli.File("/vela/secrets/terraform/version"),
 				),
 			},
 
-			// Config Flags
+			&cli.StringFlag{
+				Name:  "terraform.cache_dir",
+				Value: cacheDir(),
+				Usage: "directory to cache installed terraform versions in",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_CACHE_DIR"),
+					cli.EnvVar("TERRAFORM_CACHE_DIR"),
+					cli.File("/vela/parameters/terraform/cache_dir"),
+					cli.File("/vela/secrets/terraform/cache_dir"),
+				),
+			},
+			&cli.StringFlag{
+				Name:  "terraform.mirror",
+				Usage: "directory or file:// URL of a mirror of the terraform releases site to install from",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_TERRAFORM_MIRROR"),
+					cli.EnvVar("TERRAFORM_TERRAFORM_MIRROR"),
+					cli.File("/vela/parameters/terraform/terraform_mirror"),
+					cli.File("/vela/secrets/terraform/terraform_mirror"),
+				),
+			},
+			&cli.StringFlag{
+				Name:  "terraform.mirror_key",
+				Usage: "armored GPG public key to verify the mirror checksums signature",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_TERRAFORM_MIRROR_KEY"),
+					cli.EnvVar("TERRAFORM_TERRAFORM_MIRROR_KEY"),
+					cli.File("/vela/parameters/terraform/terraform_mirror_key"),
+					cli.File("/vela/secrets/terraform/terraform_mirror_key"),
+				),
+			},
+			&cli.StringFlag{
+				Name:  "tofu.checksum",
+				Usage: "SHA256 checksum the OpenTofu release zip must match",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_TOFU_CHECKSUM"),
+					cli.EnvVar("TERRAFORM_TOFU_CHECKSUM"),
+					cli.File("/vela/parameters/terraform/tofu_checksum"),
+					cli.File("/vela/secrets/terraform/tofu_checksum"),
+				),
+			},
+			&cli.StringFlag{
+				Name:  "tofu.key",
+				Usage: "armored GPG public key to verify the OpenTofu checksums signature",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_TOFU_KEY"),
+					cli.EnvVar("TERRAFORM_TOFU_KEY"),
+					cli.File("/vela/parameters/terraform/tofu_key"),
+					cli.File("/vela/secrets/terraform/tofu_key"),
+				),
+			},
+
+			// Changes Flags
 
+			&cli.BoolFlag{
+				Name:  "changes.only",
+				Usage: "only run the directories affected by the changes in the build",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_CHANGED_ONLY"),
+					cli.EnvVar("TERRAFORM_CHANGED_ONLY"),
+					cli.File("/vela/parameters/terraform/changed_only"),
+					cli.File("/vela/secrets/terraform/changed_only"),
+				),
+			},
+			&cli.StringFlag{
+				Name:  "changes.base_ref",
+				Usage: "git ref to detect the changes in the build against",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_BASE_REF"),
+					cli.EnvVar("TERRAFORM_BASE_REF"),
+					cli.File("/vela/parameters/terraform/base_ref"),
+					cli.File("/vela/secrets/terraform/base_ref"),
+					cli.EnvVar("VELA_BUILD_BASE_REF"),
+				),
+			},
 			&cli.StringFlag{
-				Name:  "config.action",
-				Usage: "the action to have terraform perform",
+				Name:  "changes.commit",
+				Usage: "git commit to detect the changes in the build for",
 				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_COMMIT"),
+					cli.EnvVar("TERRAFORM_COMMIT"),
+					cli.File("/vela/parameters/terraform/commit"),
+					cli.File("/vela/secrets/terraform/commit"),
+					cli.EnvVar("VELA_BUILD_COMMIT"),
+				),
+			},
+
+			// Config Flags
+
+			&cli.StringSliceFlag{
+				Name:    "config.actions",
+				Aliases: []string{"config.action"},
+				Usage:   "the actions to have terraform perform in order",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_ACTIONS"),
+					cli.EnvVar("TERRAFORM_ACTIONS"),
 					cli.EnvVar("PARAMETER_ACTION"),
 					cli.EnvVar("TERRAFORM_ACTION"),
+					cli.File("/vela/parameters/terraform/actions"),
+					cli.File("/vela/secrets/terraform/actions"),
 					cli.File("/vela/parameters/terraform/action"),
 					cli.File("/vela/secrets/terraform/action"),
 				),
 			},
+			&cli.StringFlag{
+				Name:  "config.registry_credentials",
+				Usage: "a YAML or JSON map of private registry hosts to API tokens",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_REGISTRY_CREDENTIALS"),
+					cli.EnvVar("TERRAFORM_REGISTRY_CREDENTIALS"),
+					cli.File("/vela/parameters/terraform/registry_credentials"),
+					cli.File("/vela/secrets/terraform/registry_credentials"),
+				),
+			},
+
+			// Directories Flags
+
+			&cli.StringSliceFlag{
+				Name:  "directories.paths",
+				Usage: "paths or glob patterns for the directories to run the actions in",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_DIRECTORIES"),
+					cli.EnvVar("TERRAFORM_DIRECTORIES"),
+					cli.File("/vela/parameters/terraform/directories"),
+					cli.File("/vela/secrets/terraform/directories"),
+				),
+			},
+			&cli.IntFlag{
+				Name:  "directories.concurrency",
+				Value: 1,
+				Usage: "number of directories to run in parallel",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_CONCURRENCY"),
+					cli.EnvVar("TERRAFORM_CONCURRENCY"),
+					cli.File("/vela/parameters/terraform/concurrency"),
+					cli.File("/vela/secrets/terraform/concurrency"),
+				),
+			},
+			&cli.StringFlag{
+				Name:  "directories.depends_on",
+				Usage: "path to the YAML or JSON file with the dependencies of each directory",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_DEPENDS_ON"),
+					cli.EnvVar("TERRAFORM_DEPENDS_ON"),
+					cli.File("/vela/parameters/terraform/depends_on"),
+					cli.File("/vela/secrets/terraform/depends_on"),
+				),
+			},
+			&cli.BoolFlag{
+				Name:  "directories.detect_dependencies",
+				Usage: "detect the dependencies of each directory from terraform_remote_state data sources",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_DETECT_DEPENDENCIES"),
+					cli.EnvVar("TERRAFORM_DETECT_DEPENDENCIES"),
+					cli.File("/vela/parameters/terraform/detect_dependencies"),
+					cli.File("/vela/secrets/terraform/detect_dependencies"),
+				),
+			},
+			&cli.BoolFlag{
+				Name:  "directories.continue_on_error",
+				Usage: "run the remaining directories when one fails",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_CONTINUE_ON_ERROR"),
+					cli.EnvVar("TERRAFORM_CONTINUE_ON_ERROR"),
+					cli.File("/vela/parameters/terraform/continue_on_error"),
+					cli.File("/vela/secrets/terraform/continue_on_error"),
+				),
+			},
 
 			// FMT Flags
 
@@ -259,6 +532,16 @@ func main() {
 
 			// InitOptions Flags
 
+			&cli.StringFlag{
+				Name:  "init.archive",
+				Usage: "path to write or read an archive of the initialized working directory",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_INIT_ARCHIVE"),
+					cli.EnvVar("TERRAFORM_INIT_ARCHIVE"),
+					cli.File("/vela/parameters/terraform/init_archive"),
+					cli.File("/vela/secrets/terraform/init_archive"),
+				),
+			},
 			&cli.StringFlag{
 				Name:  "init.options",
 				Usage: "properties to set on terraform init action",
@@ -306,6 +589,74 @@ func main() {
 					cli.File("/vela/secrets/terraform/password"),
 				),
 			},
+			&cli.StringFlag{
+				Name:  "netrc.entries",
+				Usage: "a YAML or JSON list of additional netrc entries with a machine, login and password",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_NETRC_ENTRIES"),
+					cli.EnvVar("TERRAFORM_NETRC_ENTRIES"),
+					cli.File("/vela/parameters/terraform/netrc_entries"),
+					cli.File("/vela/secrets/terraform/netrc_entries"),
+				),
+			},
+
+			// SSH Flags
+
+			&cli.StringFlag{
+				Name:  "ssh.key",
+				Usage: "private key for cloning modules over SSH",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_SSH_KEY"),
+					cli.EnvVar("TERRAFORM_SSH_KEY"),
+					cli.File("/vela/parameters/terraform/ssh_key"),
+					cli.File("/vela/secrets/terraform/ssh_key"),
+				),
+			},
+			&cli.StringFlag{
+				Name:  "ssh.known_hosts",
+				Usage: "known hosts entries to verify the hosts when cloning modules over SSH",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_SSH_KNOWN_HOSTS"),
+					cli.EnvVar("TERRAFORM_SSH_KNOWN_HOSTS"),
+					cli.File("/vela/parameters/terraform/ssh_known_hosts"),
+					cli.File("/vela/secrets/terraform/ssh_known_hosts"),
+				),
+			},
+			&cli.BoolFlag{
+				Name:  "ssh.trust_on_first_use",
+				Usage: "trust the keys of the hosts on the first connection when no known hosts are provided",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_SSH_TRUST_ON_FIRST_USE"),
+					cli.EnvVar("TERRAFORM_SSH_TRUST_ON_FIRST_USE"),
+					cli.File("/vela/parameters/terraform/ssh_trust_on_first_use"),
+					cli.File("/vela/secrets/terraform/ssh_trust_on_first_use"),
+				),
+			},
+
+			// Output Flags
+
+			&cli.StringFlag{
+				Name:  "output.env_file",
+				Usage: "path to the env file to write non-sensitive outputs to",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_OUTPUT_FILE"),
+					cli.EnvVar("TERRAFORM_OUTPUT_FILE"),
+					cli.File("/vela/parameters/terraform/output_file"),
+					cli.File("/vela/secrets/terraform/output_file"),
+					cli.EnvVar("VELA_OUTPUTS"),
+				),
+			},
+			&cli.StringFlag{
+				Name:  "output.masked_env_file",
+				Usage: "path to the env file to write sensitive outputs to",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_MASKED_OUTPUT_FILE"),
+					cli.EnvVar("TERRAFORM_MASKED_OUTPUT_FILE"),
+					cli.File("/vela/parameters/terraform/masked_output_file"),
+					cli.File("/vela/secrets/terraform/masked_output_file"),
+					cli.EnvVar("VELA_MASKED_OUTPUTS"),
+				),
+			},
 
 			// Plan Flags
 
@@ -339,6 +690,16 @@ func main() {
 					cli.File("/vela/secrets/terraform/input"),
 				),
 			},
+			&cli.StringFlag{
+				Name:  "plan.out",
+				Usage: "path to write the plan file",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_PLAN_OUT"),
+					cli.EnvVar("TERRAFORM_PLAN_OUT"),
+					cli.File("/vela/parameters/terraform/plan_out"),
+					cli.File("/vela/secrets/terraform/plan_out"),
+				),
+			},
 			&cli.IntFlag{
 				Name:  "plan.module_depth",
 				Usage: "specifies the depth of modules to show in the output",
@@ -362,6 +723,40 @@ func main() {
 					cli.File("/vela/secrets/terraform/check_variables"),
 				),
 			},
+
+			// Workspace Flags
+
+			&cli.StringFlag{
+				Name:  "workspace.name",
+				Usage: "the workspace to select for the working directory",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_WORKSPACE"),
+					cli.EnvVar("TERRAFORM_WORKSPACE"),
+					cli.File("/vela/parameters/terraform/workspace"),
+					cli.File("/vela/secrets/terraform/workspace"),
+				),
+			},
+			&cli.BoolFlag{
+				Name:  "workspace.create",
+				Usage: "create the workspace when it does not exist",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_CREATE_WORKSPACE"),
+					cli.EnvVar("TERRAFORM_CREATE_WORKSPACE"),
+					cli.File("/vela/parameters/terraform/create_workspace"),
+					cli.File("/vela/secrets/terraform/create_workspace"),
+				),
+			},
+			&cli.StringFlag{
+				Name:  "workspace.command",
+				Value: "list",
+				Usage: "workspace command to run for the workspace action - options: (list|new|delete)",
+				Sources: cli.NewValueSourceChain(
+					cli.EnvVar("PARAMETER_WORKSPACE_COMMAND"),
+					cli.EnvVar("TERRAFORM_WORKSPACE_COMMAND"),
+					cli.File("/vela/parameters/terraform/workspace_command"),
+					cli.File("/vela/secrets/terraform/workspace_command"),
+				),
+			},
 		},
 	}
 
@@ -399,11 +794,44 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		"registry": "https://hub.docker.com/r/target/vela-terraform",
 	}).Info("Vela Terraform Plugin")
 
-	// capture custom terraform tfVersion requested
-	tfVersion := cmd.String("terraform.version")
+	// select the engine used to run the configuration
+	err := selectEngine(cmd.String("engine"))
+	if err != nil {
+		return err
+	}
+
+	// capture the version installed in the image which is used when no version is provided
+	defaultVer := os.Getenv("PLUGIN_TERRAFORM_VERSION")
 
-	// attempt to install the custom terraform tfVersion if different from default
-	err := installBinary(ctx, tfVersion, os.Getenv("PLUGIN_TERRAFORM_VERSION"))
+	// the image only includes a default terraform binary
+	if engine.Name != _terraformEngine {
+		defaultVer = ""
+	}
+
+	// variable to store the mirror to install terraform from
+	var mirror *Mirror
+
+	// check if a mirror was provided
+	if len(cmd.String("terraform.mirror")) > 0 {
+		mirror = &Mirror{
+			URL: cmd.String("terraform.mirror"),
+			Key: cmd.String("terraform.mirror_key"),
+		}
+	}
+
+	// attempt to install the custom terraform version if different from default
+	tfVersion, err := installBinary(
+		ctx,
+		cmd.String("terraform.version"),
+		defaultVer,
+		cmd.String("directory"),
+		cmd.String("terraform.cache_dir"),
+		mirror,
+		&Tofu{
+			Checksum: cmd.String("tofu.checksum"),
+			Key:      cmd.String("tofu.key"),
+		},
+	)
 	if err != nil {
 		return err
 	}
@@ -414,6 +842,13 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
+	// create the guardrails shared by plan and apply
+	guardrails := &Guardrails{
+		DenyDestroyTypes: cmd.StringSlice("deny_destroy_types"),
+		MaxChanges:       cmd.Int("max_changes"),
+		MaxDestroy:       cmd.Int("max_destroy"),
+	}
+
 	// create the plugin
 	p := Plugin{
 		// Apply configuration
@@ -421,43 +856,75 @@ func run(ctx context.Context, cmd *cli.Command) error {
 			AutoApprove: cmd.Bool("auto_approve"),
 			Backup:      cmd.String("backup"),
 			Directory:   cmd.String("directory"),
+			Guardrails:  guardrails,
 			Lock:        cmd.Bool("lock"),
 			LockTimeout: cmd.Duration("lock_timeout"),
 			NoColor:     cmd.Bool("no_color"),
 			Parallelism: cmd.Int("parallelism"),
+			PlanFile:    cmd.String("plan_file"),
 			Refresh:     cmd.Bool("refresh"),
+			Replace:     cmd.StringSlice("replace"),
 			State:       cmd.String("state"),
 			StateOut:    cmd.String("state_out"),
-			Target:      cmd.String("target"),
+			Targets:     cmd.StringSlice("targets"),
 			Vars:        cmd.StringSlice("vars"),
 			VarFiles:    cmd.StringSlice("var_files"),
 			Version:     tfSemVersion,
 		},
+		// Changes configuration
+		Changes: &Changes{
+			BaseRef: cmd.String("changes.base_ref"),
+			Commit:  cmd.String("changes.commit"),
+			Only:    cmd.Bool("changes.only"),
+		},
 		// Config configuration
 		Config: &Config{
-			Action: cmd.String("config.action"),
+			Actions: cmd.StringSlice("config.actions"),
 			Netrc: &Netrc{
 				Login:    cmd.String("netrc.username"),
 				Machine:  cmd.String("netrc.machine"),
 				Password: cmd.String("netrc.password"),
 			},
+			RawNetrcs:              cmd.String("netrc.entries"),
+			RawRegistryCredentials: cmd.String("config.registry_credentials"),
+			SSH: &SSH{
+				Key:             cmd.String("ssh.key"),
+				KnownHosts:      cmd.String("ssh.known_hosts"),
+				TrustOnFirstUse: cmd.Bool("ssh.trust_on_first_use"),
+			},
 		},
 		// Destroy configuration
 		Destroy: &Destroy{
-			AutoApprove: cmd.Bool("auto_approve"),
-			Backup:      cmd.String("backup"),
-			Directory:   cmd.String("directory"),
-			Lock:        cmd.Bool("lock"),
-			LockTimeout: cmd.Duration("lock_timeout"),
-			NoColor:     cmd.Bool("no_color"),
-			Parallelism: cmd.Int("parallelism"),
-			Refresh:     cmd.Bool("refresh"),
-			State:       cmd.String("state"),
-			StateOut:    cmd.String("state_out"),
-			Target:      cmd.String("target"),
-			Vars:        cmd.StringSlice("vars"),
-			VarFiles:    cmd.StringSlice("var_files"),
-			Version:     tfSemVersion,
+			AutoApprove:       cmd.Bool("auto_approve"),
+			Backup:            cmd.String("backup"),
+			Confirm:           cmd.StringSlice("confirm_destroy"),
+			Directory:         cmd.String("directory"),
+			Lock:              cmd.Bool("lock"),
+			LockTimeout:       cmd.Duration("lock_timeout"),
+			NoColor:           cmd.Bool("no_color"),
+			Parallelism:       cmd.Int("parallelism"),
+			ProtectedPatterns: cmd.StringSlice("protected_patterns"),
+			Refresh:           cmd.Bool("refresh"),
+			Replace:           cmd.StringSlice("replace"),
+			State:             cmd.String("state"),
+			StateOut:          cmd.String("state_out"),
+			Targets:           cmd.StringSlice("targets"),
+			Vars:              cmd.StringSlice("vars"),
+			VarFiles:          cmd.StringSlice("var_files"),
+			Version:           tfSemVersion,
+		},
+		// Directories configuration
+		Directories: &Directories{
+			Concurrency:        cmd.Int("directories.concurrency"),
+			ContinueOnError:    cmd.Bool("directories.continue_on_error"),
+			DetectDependencies: cmd.Bool("directories.detect_dependencies"),
+			Manifest:           cmd.String("directories.depends_on"),
+			Patterns:           cmd.StringSlice("directories.paths"),
+		},
+		// Environment configuration
+		Environment: &Environment{
+			RawVarEnvMap: cmd.String("var_env_map"),
+			VarPrefix:    cmd.Bool("var_prefix"),
 		},
 		// FMT configuration
 		FMT: &FMT{
@@ -470,14 +937,24 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		},
 		// InitOptions configuration
 		Init: &Init{
+			Archive:   cmd.String("init.archive"),
 			Directory: cmd.String("directory"),
 			RawInit:   cmd.String("init.options"),
+			Version:   tfSemVersion,
+		},
+		// Output configuration
+		Output: &Output{
+			Directory:     cmd.String("directory"),
+			EnvFile:       cmd.String("output.env_file"),
+			MaskedEnvFile: cmd.String("output.masked_env_file"),
+			Version:       tfSemVersion,
 		},
 		// Plan configuration
 		Plan: &Plan{
 			Destroy:          cmd.Bool("plan.destroy"),
 			DetailedExitCode: cmd.Bool("plan.detailed_exit_code"),
 			Directory:        cmd.String("directory"),
+			Guardrails:       guardrails,
 			Input:            cmd.Bool("plan.input"),
 			Lock:             cmd.Bool("lock"),
 			LockTimeout:      cmd.Duration("lock_timeout"),
@@ -485,9 +962,10 @@ func run(ctx context.Context, cmd *cli.Command) error {
 			NoColor:          cmd.Bool("no_color"),
 			Parallelism:      cmd.Int("parallelism"),
 			Refresh:          cmd.Bool("refresh"),
+			Replace:          cmd.StringSlice("replace"),
 			State:            cmd.String("state"),
-			Out:              cmd.String("state_out"),
-			Target:           cmd.String("target"),
+			Out:              cmd.String("plan.out"),
+			Targets:          cmd.StringSlice("targets"),
 			Vars:             cmd.StringSlice("vars"),
 			VarFiles:         cmd.StringSlice("var_files"),
 			Version:          tfSemVersion,
@@ -501,6 +979,18 @@ func run(ctx context.Context, cmd *cli.Command) error {
 			VarFiles:       cmd.StringSlice("var_files"),
 			Version:        tfSemVersion,
 		},
+		// Variables configuration
+		Variables: &Variables{
+			Raw: cmd.String("variables"),
+		},
+		// Workspace configuration
+		Workspace: &Workspace{
+			Command:   cmd.String("workspace.command"),
+			Create:    cmd.Bool("workspace.create"),
+			Directory: cmd.String("directory"),
+			Name:      cmd.String("workspace.name"),
+			Version:   tfSemVersion,
+		},
 	}
 
 	// validate the plugin
@@ -511,15 +1001,3 @@ func run(ctx context.Context, cmd *cli.Command) error {
 
 	return p.Exec(ctx)
 }
-
-func SupportsChdir(v *semver.Version) bool {
-	if v.Major() >= 1 {
-		return true
-	}
-
-	if v.Minor() >= 14 {
-		return true
-	}
-
-	return false
-}
@@ -0,0 +1,237 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// _mask represents the value secrets are replaced with.
+	_mask = "***"
+	// _minSecretLength represents the shortest value masked to avoid
+	// replacing common values i.e. "." or "true" throughout the output.
+	_minSecretLength = 4
+	// _maxPending represents the most output held while waiting for a newline.
+	_maxPending = 64 * 1024
+)
+
+var (
+	// sensitiveVariable represents the regexp to match the start of a variable block.
+	sensitiveVariable = regexp.MustCompile(`\bvariable\s+"([^"]+)"\s*\{`)
+	// sensitiveAttribute represents the regexp to match a variable marked sensitive.
+	sensitiveAttribute = regexp.MustCompile(`\bsensitive\s*=\s*true\b`)
+)
+
+// maskerKey represents the context key for the masker.
+type maskerKey struct{}
+
+// Masker represents the secrets to replace in the output of the plugin.
+type Masker struct {
+	mu      sync.RWMutex
+	secrets []string
+}
+
+// withMasker returns a copy of the context which masks the
+// secrets in the output of any commands run with it.
+func withMasker(ctx context.Context, m *Masker) context.Context {
+	return context.WithValue(ctx, maskerKey{}, m)
+}
+
+// masker is a helper function to return the masker for the context.
+func masker(ctx context.Context) *Masker {
+	m, _ := ctx.Value(maskerKey{}).(*Masker)
+
+	return m
+}
+
+// Add adds the secrets to replace in the output.
+func (m *Masker) Add(secrets ...string) {
+	// check if a masker is provided
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, secret := range secrets {
+		// mask each line of multi-line secrets since output is masked by line
+		for line := range strings.Lines(secret) {
+			line = strings.TrimRight(line, "\r\n")
+
+			// skip any short or duplicate secrets
+			if len(strings.TrimSpace(line)) < _minSecretLength || slices.Contains(m.secrets, line) {
+				continue
+			}
+
+			m.secrets = append(m.secrets, line)
+		}
+	}
+
+	// replace the longest secrets first in case one contains another
+	slices.SortFunc(m.secrets, func(a, b string) int { return len(b) - len(a) })
+}
+
+// Mask returns the string with each of the secrets replaced.
+func (m *Masker) Mask(s string) string {
+	// check if a masker is provided
+	if m == nil {
+		return s
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, secret := range m.secrets {
+		s = strings.ReplaceAll(s, secret, _mask)
+	}
+
+	return s
+}
+
+// Writer returns a writer replacing the secrets in
+// each line of the output before writing it to w.
+func (m *Masker) Writer(w io.Writer) io.Writer {
+	// check if a masker is provided
+	if m == nil {
+		return w
+	}
+
+	return &maskWriter{masker: m, w: w}
+}
+
+// maskWriter represents a writer replacing the secrets in the output.
+type maskWriter struct {
+	masker *Masker
+	w      io.Writer
+	// partial line held until the rest of the line is written
+	pending []byte
+}
+
+// Write masks each complete line of the output and
+// holds any partial line until the rest is written.
+func (w *maskWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+
+	// find the end of the last complete line
+	i := bytes.LastIndexByte(w.pending, '\n')
+
+	// write the partial line when it grows too large to hold
+	if i < 0 && len(w.pending) < _maxPending {
+		return len(p), nil
+	}
+
+	if i < 0 {
+		i = len(w.pending) - 1
+	}
+
+	_, err := io.WriteString(w.w, w.masker.Mask(string(w.pending[:i+1])))
+
+	w.pending = slices.Clone(w.pending[i+1:])
+
+	return len(p), err
+}
+
+// Flush masks and writes any partial line held by the writer.
+func (w *maskWriter) Flush() error {
+	// check if any output is held
+	if len(w.pending) == 0 {
+		return nil
+	}
+
+	_, err := io.WriteString(w.w, w.masker.Mask(string(w.pending)))
+
+	w.pending = nil
+
+	return err
+}
+
+// flush is a helper function to write any
+// output held by the provided writers.
+func flush(writers ...io.Writer) {
+	for _, w := range writers {
+		f, ok := w.(interface{ Flush() error })
+		if !ok {
+			continue
+		}
+
+		err := f.Flush()
+		if err != nil {
+			logrus.Debugf("unable to flush output: %v", err)
+		}
+	}
+}
+
+// readSecrets is a helper function to read the values of the secret
+// files mapped to terraform variables since the other files mounted
+// by Vela may hold plugin parameters i.e. "directory" or "auto_approve".
+func readSecrets() ([]string, error) {
+	// read the same values exported as terraform variables
+	vars, err := secretVars()
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Collect(maps.Values(vars)), nil
+}
+
+// sensitiveValues is a helper function to return the values provided for the
+// variables marked sensitive in the configuration of the directory.
+func (p *Plugin) sensitiveValues() ([]string, error) {
+	src, err := readConfig(p.Init.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+
+	for _, loc := range sensitiveVariable.FindAllSubmatchIndex(src, -1) {
+		// skip any variables not marked sensitive
+		if !sensitiveAttribute.MatchString(blockBody(src, loc[1])) {
+			continue
+		}
+
+		name := string(src[loc[2]:loc[3]])
+
+		// capture the value from the vars i.e. "db_password=hunter2"
+		for _, vars := range [][]string{p.Apply.Vars, p.Destroy.Vars, p.Plan.Vars, p.Validation.Vars} {
+			for _, v := range vars {
+				value, ok := strings.CutPrefix(v, name+"=")
+				if ok {
+					values = append(values, value)
+				}
+			}
+		}
+
+		// capture the value from the structured variables
+		if raw, ok := p.Variables.values[name]; ok {
+			var s string
+
+			// check if the value is a string to mask it without quotes
+			if json.Unmarshal(raw, &s) == nil {
+				values = append(values, s)
+			} else {
+				values = append(values, string(raw))
+			}
+		}
+
+		// capture the value from the environment
+		if value, ok := os.LookupEnv("TF_VAR_" + name); ok {
+			values = append(values, value)
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"slices"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestTerraform_Masker_Mask(t *testing.T) {
+	// setup types
+	m := new(Masker)
+	m.Add("hunter2", "", ".", "abc", "hunter2", "-----BEGIN KEY-----\nabc123\n-----END KEY-----\n", "hunter22")
+
+	got := m.Mask("password=hunter22 other=hunter2 key=abc123 dir=. short=abc")
+	want := "password=*** other=*** key=*** dir=. short=abc"
+
+	if got != want {
+		t.Errorf("Mask is %q, want %q", got, want)
+	}
+}
+
+func TestTerraform_Masker_Mask_Nil(t *testing.T) {
+	// setup types
+	var m *Masker
+
+	m.Add("hunter2")
+
+	got := m.Mask("hunter2")
+
+	if got != "hunter2" {
+		t.Errorf("Mask is %q, want %q", got, "hunter2")
+	}
+}
+
+func TestTerraform_Masker_Writer(t *testing.T) {
+	// setup types
+	m := new(Masker)
+	m.Add("hunter2")
+
+	b := new(bytes.Buffer)
+
+	w := m.Writer(b)
+
+	// write the secret split across writes
+	for _, p := range []string{"password = \"hun", "ter2\"\nnext", " line hunter2"} {
+		_, err := w.Write([]byte(p))
+		if err != nil {
+			t.Errorf("Write returned err: %v", err)
+		}
+	}
+
+	want := "password = \"***\"\n"
+
+	if b.String() != want {
+		t.Errorf("Writer output is %q, want %q", b.String(), want)
+	}
+
+	flush(w)
+
+	want += "next line ***"
+
+	if b.String() != want {
+		t.Errorf("Writer output is %q, want %q", b.String(), want)
+	}
+}
+
+func TestTerraform_execCmd_withMasker(t *testing.T) {
+	// setup types
+	m := new(Masker)
+	m.Add("hunter2")
+
+	w := new(bytes.Buffer)
+
+	e := exec.CommandContext(t.Context(), "echo", "-n", "hunter2")
+
+	err := execCmd(withMasker(withOutput(t.Context(), w), m), e)
+	if err != nil {
+		t.Errorf("execCmd returned err: %v", err)
+	}
+
+	want := "$ echo -n ***\n***"
+
+	if w.String() != want {
+		t.Errorf("execCmd output is %q, want %q", w.String(), want)
+	}
+}
+
+func TestTerraform_readSecrets(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	files := map[string]string{
+		"/vela/secrets/terraform/auto_approve":   "true",
+		"/vela/secrets/terraform/directory":      ".",
+		"/vela/secrets/terraform/vars/api_token": "abc123\n",
+		"/vela/secrets/terraform/vars/db_secret": "hunter2",
+	}
+
+	for path, content := range files {
+		err := afero.WriteFile(appFS, path, []byte(content), 0600)
+		if err != nil {
+			t.Errorf("unable to create file %s: %v", path, err)
+		}
+	}
+
+	got, err := readSecrets()
+	if err != nil {
+		t.Errorf("readSecrets returned err: %v", err)
+	}
+
+	slices.Sort(got)
+
+	want := []string{"abc123", "hunter2"}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("readSecrets is %v, want %v", got, want)
+	}
+}
+
+func TestTerraform_readSecrets_NoSecrets(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	got, err := readSecrets()
+	if err != nil {
+		t.Errorf("readSecrets returned err: %v", err)
+	}
+
+	if len(got) > 0 {
+		t.Errorf("readSecrets is %v, want none", got)
+	}
+}
+
+func TestTerraform_Plugin_sensitiveValues(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	config := `
+variable "db_password" {
+  type      = string
+  sensitive = true
+}
+
+variable "api_token" {
+  sensitive = true
+}
+
+variable "region" {
+  default = "us-east-1"
+}
+`
+
+	err := afero.WriteFile(appFS, "foobar/variables.tf", []byte(config), 0644)
+	if err != nil {
+		t.Errorf("unable to create file: %v", err)
+	}
+
+	t.Setenv("TF_VAR_api_token", "abc123")
+
+	// setup types
+	p := &Plugin{
+		Apply:      &Apply{Vars: []string{"db_password=hunter2", "region=us-west-2"}},
+		Destroy:    &Destroy{},
+		Init:       &Init{Directory: "foobar"},
+		Plan:       &Plan{},
+		Validation: &Validation{},
+		Variables:  &Variables{Raw: `{"api_token": "xyz789", "region": "us-east-2"}`},
+	}
+
+	err = p.Variables.Unmarshal()
+	if err != nil {
+		t.Errorf("Unmarshal returned err: %v", err)
+	}
+
+	got, err := p.sensitiveValues()
+	if err != nil {
+		t.Errorf("sensitiveValues returned err: %v", err)
+	}
+
+	want := []string{"hunter2", "xyz789", "abc123"}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("sensitiveValues is %v, want %v", got, want)
+	}
+}
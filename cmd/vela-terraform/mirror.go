@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/hashicorp/go-version"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+var (
+	// ErrChecksumMismatch defines the error type when a mirrored
+	// release doesn't match the checksum in its SHA256SUMS file.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrInvalidMirror defines the error type when the
+	// mirror provided to the plugin is unsupported.
+	ErrInvalidMirror = errors.New("invalid terraform mirror provided")
+)
+
+// Mirror represents the plugin configuration for installing
+// the engine from a local copy of the releases site.
+type Mirror struct {
+	// directory or file:// URL laid out like releases.hashicorp.com
+	URL string
+	// armored GPG public key used to verify the SHA256SUMS signature
+	Key string
+}
+
+// Dir returns the local directory for the mirror.
+func (m *Mirror) Dir() (string, error) {
+	u, err := url.Parse(m.URL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidMirror, err)
+	}
+
+	switch u.Scheme {
+	case "":
+		return m.URL, nil
+	case "file":
+		return u.Path, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported scheme %s", ErrInvalidMirror, u.Scheme)
+	}
+}
+
+// Versions returns the engine versions available from
+// the mirror matching the constraints in ascending order.
+func (m *Mirror) Versions(constraints version.Constraints) ([]*version.Version, error) {
+	logrus.Tracef("listing %s versions from mirror %s", engine.Name, m.URL)
+
+	dir, err := m.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	entries, err := a.ReadDir(filepath.Join(dir, engine.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	var versions version.Collection
+
+	for _, entry := range entries {
+		// skip any files or directories that aren't versions
+		v, err := version.NewVersion(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		// skip any versions that don't match the constraints
+		if !constraints.Check(v) {
+			continue
+		}
+
+		versions = append(versions, v)
+	}
+
+	sort.Sort(versions)
+
+	return versions, nil
+}
+
+// Install verifies and extracts the engine
+// release for the version from the mirror to path.
+func (m *Mirror) Install(v *version.Version, path string) error {
+	logrus.Infof("installing %s %s from mirror %s", engine.Name, v, m.URL)
+
+	dir, err := m.Dir()
+	if err != nil {
+		return err
+	}
+
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	// releases are stored in a directory per version i.e. "terraform/1.5.7/"
+	releaseDir := filepath.Join(dir, engine.Name, v.String())
+	sumsFile := fmt.Sprintf("%s_%s_SHA256SUMS", engine.Name, v)
+	zipFile := fmt.Sprintf("%s_%s_%s_%s.zip", engine.Name, v, runtime.GOOS, runtime.GOARCH)
+
+	sums, err := a.ReadFile(filepath.Join(releaseDir, sumsFile))
+	if err != nil {
+		return err
+	}
+
+	// check if a key was provided to verify the checksums
+	if len(m.Key) > 0 {
+		sig, err := a.ReadFile(filepath.Join(releaseDir, sumsFile+".sig"))
+		if err != nil {
+			return err
+		}
+
+		err = verifySignature(m.Key, sums, sig)
+		if err != nil {
+			return err
+		}
+	} else {
+		logrus.Warn("no terraform_mirror_key provided, skipping signature verification")
+	}
+
+	release, err := a.ReadFile(filepath.Join(releaseDir, zipFile))
+	if err != nil {
+		return err
+	}
+
+	err = verifyChecksum(sums, zipFile, release)
+	if err != nil {
+		return err
+	}
+
+	return extractBinary(a, release, engine.Name, path)
+}
+
+// verifySignature is a helper function to verify the
+// checksums were signed by the provided armored key.
+func verifySignature(key string, sums, sig []byte) error {
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(key))
+	if err != nil {
+		return fmt.Errorf("unable to read GPG key: %w", err)
+	}
+
+	_, err = openpgp.CheckDetachedSignature(keyring, bytes.NewReader(sums), bytes.NewReader(sig), nil)
+	if err != nil {
+		return fmt.Errorf("unable to verify checksums signature: %w", err)
+	}
+
+	return nil
+}
+
+// verifyChecksum is a helper function to verify the release
+// matches the checksum for the file name in the checksums.
+func verifyChecksum(sums []byte, name string, release []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+
+	for scanner.Scan() {
+		// each line is formatted as "<checksum>  <file>"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[1] != name {
+			continue
+		}
+
+		sum := sha256.Sum256(release)
+
+		if !strings.EqualFold(fields[0], hex.EncodeToString(sum[:])) {
+			return fmt.Errorf("%w: %s", ErrChecksumMismatch, name)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: no checksum found for %s", ErrChecksumMismatch, name)
+}
+
+// extractBinary is a helper function to write the
+// named binary from the release zip to path.
+func extractBinary(a *afero.Afero, release []byte, name, path string) error {
+	r, err := zip.NewReader(bytes.NewReader(release), int64(len(release)))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range r.File {
+		// skip any files other than the binary i.e. "LICENSE.txt"
+		if f.Name != name {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+
+		return writeBinary(a, rc, path)
+	}
+
+	return fmt.Errorf("%w: release does not contain %s", ErrInvalidMirror, name)
+}
+
+// writeBinary is a helper function to write the binary to a temporary
+// file and move it to path once complete so a failed or interrupted
+// install never leaves a partial binary in the cache.
+func writeBinary(a *afero.Afero, r io.Reader, path string) (err error) {
+	tmp, err := a.TempFile(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+
+	// remove the temporary file when it isn't moved to path
+	defer func() {
+		if err != nil {
+			_ = a.Remove(tmp.Name())
+		}
+	}()
+
+	//nolint:gosec // ignore G110 since releases are verified by checksum
+	_, err = io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	err = a.Chmod(tmp.Name(), 0755)
+	if err != nil {
+		return err
+	}
+
+	return a.Rename(tmp.Name(), path)
+}
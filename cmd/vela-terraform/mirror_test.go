@@ -0,0 +1,304 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/hashicorp/go-version"
+	"github.com/spf13/afero"
+)
+
+// setupMirror is a helper function to create a mirror
+// in the filesystem with a release for each version.
+func setupMirror(t *testing.T, signer *openpgp.Entity, versions ...string) {
+	t.Helper()
+
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	for _, v := range versions {
+		dir := filepath.Join("/mirror", "terraform", v)
+		name := fmt.Sprintf("terraform_%s_%s_%s.zip", v, runtime.GOOS, runtime.GOARCH)
+
+		// create the release zip with the binary
+		release := releaseZip(t, "terraform", "terraform "+v)
+
+		sums := fmt.Appendf(nil, "%x  %s\n", sha256.Sum256(release), name)
+
+		files := map[string][]byte{
+			name: release,
+			fmt.Sprintf("terraform_%s_SHA256SUMS", v): sums,
+		}
+
+		// sign the checksums with the provided signer
+		if signer != nil {
+			sig := new(bytes.Buffer)
+
+			err := openpgp.DetachSign(sig, signer, bytes.NewReader(sums), nil)
+			if err != nil {
+				t.Fatalf("unable to sign checksums: %v", err)
+			}
+
+			files[fmt.Sprintf("terraform_%s_SHA256SUMS.sig", v)] = sig.Bytes()
+		}
+
+		for file, content := range files {
+			err := a.WriteFile(filepath.Join(dir, file), content, 0644)
+			if err != nil {
+				t.Fatalf("unable to write file %s: %v", file, err)
+			}
+		}
+	}
+}
+
+// releaseZip is a helper function to create a
+// release zip containing the named binary.
+func releaseZip(t *testing.T, name, content string) []byte {
+	t.Helper()
+
+	release := new(bytes.Buffer)
+	zw := zip.NewWriter(release)
+
+	for file, content := range map[string]string{"LICENSE.txt": "license", name: content} {
+		w, err := zw.Create(file)
+		if err != nil {
+			t.Fatalf("unable to create zip entry %s: %v", file, err)
+		}
+
+		_, err = w.Write([]byte(content))
+		if err != nil {
+			t.Fatalf("unable to write zip entry %s: %v", file, err)
+		}
+	}
+
+	err := zw.Close()
+	if err != nil {
+		t.Fatalf("unable to close zip: %v", err)
+	}
+
+	return release.Bytes()
+}
+
+// armoredKey is a helper function to create a new
+// signing entity and its armored public key.
+func armoredKey(t *testing.T) (*openpgp.Entity, string) {
+	t.Helper()
+
+	entity, err := openpgp.NewEntity("vela", "", "vela@example.com", nil)
+	if err != nil {
+		t.Fatalf("unable to create entity: %v", err)
+	}
+
+	key := new(bytes.Buffer)
+
+	w, err := armor.Encode(key, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatalf("unable to create armor: %v", err)
+	}
+
+	err = entity.Serialize(w)
+	if err != nil {
+		t.Fatalf("unable to serialize key: %v", err)
+	}
+
+	w.Close()
+
+	return entity, key.String()
+}
+
+func TestTerraform_Mirror_Dir(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		url     string
+		want    string
+		failure bool
+	}{
+		{url: "/mirror", want: "/mirror"},
+		{url: "file:///mirror", want: "/mirror"},
+		{url: "https://releases.example.com", failure: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		m := &Mirror{URL: test.url}
+
+		got, err := m.Dir()
+
+		if test.failure {
+			if !errors.Is(err, ErrInvalidMirror) {
+				t.Errorf("Dir returned err %v, want %v", err, ErrInvalidMirror)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Dir returned err: %v", err)
+		}
+
+		if got != test.want {
+			t.Errorf("Dir is %v, want %v", got, test.want)
+		}
+	}
+}
+
+func TestTerraform_Mirror_Versions(t *testing.T) {
+	setupMirror(t, nil, "1.4.6", "1.5.0", "1.5.7", "1.6.0")
+
+	// setup types
+	m := &Mirror{URL: "file:///mirror"}
+
+	constraints, _ := version.NewConstraint("~> 1.5.0")
+
+	got, err := m.Versions(constraints)
+	if err != nil {
+		t.Errorf("Versions returned err: %v", err)
+	}
+
+	if len(got) != 2 || got[0].String() != "1.5.0" || got[1].String() != "1.5.7" {
+		t.Errorf("Versions is %v, want [1.5.0 1.5.7]", got)
+	}
+}
+
+func TestTerraform_Mirror_Install(t *testing.T) {
+	signer, key := armoredKey(t)
+
+	setupMirror(t, signer, "1.5.7")
+
+	// setup types
+	m := &Mirror{URL: "/mirror", Key: key}
+
+	err := m.Install(version.Must(version.NewVersion("1.5.7")), "/bin/terraform")
+	if err != nil {
+		t.Errorf("Install returned err: %v", err)
+	}
+
+	got, _ := afero.ReadFile(appFS, "/bin/terraform")
+	if string(got) != "terraform 1.5.7" {
+		t.Errorf("Install wrote %q, want %q", got, "terraform 1.5.7")
+	}
+}
+
+func TestTerraform_Mirror_Install_BadSignature(t *testing.T) {
+	signer, _ := armoredKey(t)
+	_, key := armoredKey(t)
+
+	setupMirror(t, signer, "1.5.7")
+
+	// setup types
+	m := &Mirror{URL: "/mirror", Key: key}
+
+	err := m.Install(version.Must(version.NewVersion("1.5.7")), "/bin/terraform")
+	if err == nil {
+		t.Errorf("Install should have returned err")
+	}
+}
+
+func TestTerraform_Mirror_Install_BadChecksum(t *testing.T) {
+	setupMirror(t, nil, "1.5.7")
+
+	name := fmt.Sprintf("/mirror/terraform/1.5.7/terraform_1.5.7_%s_%s.zip", runtime.GOOS, runtime.GOARCH)
+
+	err := afero.WriteFile(appFS, name, []byte("tampered"), 0644)
+	if err != nil {
+		t.Errorf("Unable to write file: %v", err)
+	}
+
+	// setup types
+	m := &Mirror{URL: "/mirror"}
+
+	err = m.Install(version.Must(version.NewVersion("1.5.7")), "/bin/terraform")
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Install returned err %v, want %v", err, ErrChecksumMismatch)
+	}
+}
+
+func TestTerraform_install_Mirror(t *testing.T) {
+	setupMirror(t, nil, "1.5.0", "1.5.7")
+
+	t.Cleanup(func() { terraformBin = _terraform })
+
+	// run test
+	got, err := installBinary(t.Context(), "~> 1.5.0", "1.7.4", ".", "/cache", &Mirror{URL: "file:///mirror"}, nil)
+	if err != nil {
+		t.Errorf("install returned err: %v", err)
+	}
+
+	if got != "1.5.7" {
+		t.Errorf("install is %v, want %v", got, "1.5.7")
+	}
+
+	if terraformBin != "/cache/1.5.7/terraform" {
+		t.Errorf("terraformBin is %v, want %v", terraformBin, "/cache/1.5.7/terraform")
+	}
+
+	bin, _ := afero.ReadFile(appFS, terraformBin)
+	if string(bin) != "terraform 1.5.7" {
+		t.Errorf("install wrote %q, want %q", bin, "terraform 1.5.7")
+	}
+}
+
+func TestTerraform_extractBinary_Partial(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	err := a.MkdirAll("/cache/1.5.7", 0755)
+	if err != nil {
+		t.Errorf("unable to create directory: %v", err)
+	}
+
+	// create a release with an uncompressed binary to corrupt
+	release := new(bytes.Buffer)
+	zw := zip.NewWriter(release)
+
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "terraform", Method: zip.Store})
+	if err != nil {
+		t.Errorf("unable to create zip entry: %v", err)
+	}
+
+	_, err = w.Write([]byte("terraform 1.5.7"))
+	if err != nil {
+		t.Errorf("unable to write zip entry: %v", err)
+	}
+
+	err = zw.Close()
+	if err != nil {
+		t.Errorf("unable to close zip: %v", err)
+	}
+
+	// corrupt the binary so the install fails after writing part of it
+	corrupt := bytes.Replace(release.Bytes(), []byte("terraform 1.5.7"), []byte("terraform 0.0.0"), 1)
+
+	err = extractBinary(a, corrupt, "terraform", "/cache/1.5.7/terraform")
+	if err == nil {
+		t.Errorf("extractBinary should have returned err")
+	}
+
+	// verify no binary is left to be reused from the cache
+	files, err := a.ReadDir("/cache/1.5.7")
+	if err != nil {
+		t.Errorf("unable to read directory: %v", err)
+	}
+
+	if len(files) > 0 {
+		t.Errorf("extractBinary left %d files in the cache, want none", len(files))
+	}
+}
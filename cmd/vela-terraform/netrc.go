@@ -0,0 +1,326 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// _netrcSecretsDir represents the directory of the secret
+// files with additional .netrc entries i.e. ".../netrc/artifactory".
+const _netrcSecretsDir = "/vela/secrets/terraform/netrc"
+
+// blankLine represents the regexp to match the blank line ending a macdef.
+var blankLine = regexp.MustCompile(`\r?\n[ \t]*\r?\n`)
+
+// netrcTokens represents the tokens with values set for each .netrc entry.
+var netrcTokens = []string{"login", "password", "account"}
+
+// netrcEntry represents the location of an entry in the content of a .netrc file.
+type netrcEntry struct {
+	// machine name of the entry, empty for the default entry
+	machine string
+	// offset of the start of the entry
+	start int
+	// offset of the end of the machine name or default token
+	header int
+	// offsets of the value for each token i.e. "login"
+	values map[string][2]int
+}
+
+// readNetrcs is a helper function to read the
+// .netrc entries from the secret files.
+func readNetrcs() ([]*Netrc, error) {
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	// check if any secret files were mounted
+	ok, err := a.DirExists(_netrcSecretsDir)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	files, err := a.ReadDir(_netrcSecretsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*Netrc
+
+	for _, f := range files {
+		// skip any directories
+		if f.IsDir() {
+			continue
+		}
+
+		b, err := a.ReadFile(filepath.Join(_netrcSecretsDir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, parseNetrc(string(b))...)
+	}
+
+	return entries, nil
+}
+
+// parseNetrc is a helper function to return the machine entries of the .netrc content.
+func parseNetrc(content string) []*Netrc {
+	var entries []*Netrc
+
+	for _, e := range netrcEntries(content) {
+		// skip the default entry since entries are provided per machine
+		if len(e.machine) == 0 {
+			logrus.Debug("skipping default entry for netrc")
+
+			continue
+		}
+
+		n := &Netrc{Machine: e.machine}
+
+		for token, loc := range e.values {
+			value := content[loc[0]:loc[1]]
+
+			switch token {
+			case "account":
+				n.Account = value
+			case "login":
+				n.Login = value
+			case "password":
+				n.Password = value
+			}
+		}
+
+		entries = append(entries, n)
+	}
+
+	return entries
+}
+
+// netrcEntries is a helper function to return the location of each
+// entry in the .netrc content where macro definitions are skipped.
+func netrcEntries(content string) []*netrcEntry {
+	var (
+		entries []*netrcEntry
+		current *netrcEntry
+		i       int
+	)
+
+	// next returns the next whitespace separated token and its offsets
+	next := func() (string, int, int, bool) {
+		for i < len(content) && strings.ContainsRune(" \t\r\n", rune(content[i])) {
+			i++
+		}
+
+		start := i
+
+		for i < len(content) && !strings.ContainsRune(" \t\r\n", rune(content[i])) {
+			i++
+		}
+
+		return content[start:i], start, i, start < i
+	}
+
+	for {
+		token, start, end, ok := next()
+		if !ok {
+			return entries
+		}
+
+		switch token {
+		case "machine":
+			name, _, nameEnd, ok := next()
+			if !ok {
+				return entries
+			}
+
+			current = &netrcEntry{machine: name, start: start, header: nameEnd, values: make(map[string][2]int)}
+			entries = append(entries, current)
+		case "default":
+			current = &netrcEntry{start: start, header: end, values: make(map[string][2]int)}
+			entries = append(entries, current)
+		case "login", "password", "account":
+			_, valueStart, valueEnd, ok := next()
+			if !ok {
+				return entries
+			}
+
+			// skip any tokens outside an entry
+			if current != nil {
+				current.values[token] = [2]int{valueStart, valueEnd}
+			}
+		case "macdef":
+			// macro definitions continue until the next blank line
+			loc := blankLine.FindStringIndex(content[i:])
+			if loc == nil {
+				return entries
+			}
+
+			i += loc[1]
+		}
+	}
+}
+
+// mergeNetrc is a helper function to update the values of the entries in the
+// .netrc content for the machine of each update and add entries for any new
+// machines while keeping the rest of the content i.e. macro definitions.
+func mergeNetrc(content string, updates ...*Netrc) string {
+	entries := netrcEntries(content)
+
+	var (
+		// values to set for each existing entry
+		changes = make(map[int]map[string]string)
+		// entries for the machines not in the content
+		added []*Netrc
+	)
+
+	for _, update := range updates {
+		// skip any entries without credentials
+		if update == nil || (len(update.Login) == 0 && len(update.Password) == 0) {
+			continue
+		}
+
+		i := slices.IndexFunc(entries, func(e *netrcEntry) bool { return e.machine == update.Machine })
+		if i < 0 {
+			// replace any entry added for the machine by a previous update
+			j := slices.IndexFunc(added, func(n *Netrc) bool { return n.Machine == update.Machine })
+			if j < 0 {
+				added = append(added, update)
+			} else {
+				added[j] = update
+			}
+
+			continue
+		}
+
+		if changes[i] == nil {
+			changes[i] = make(map[string]string)
+		}
+
+		for token, value := range update.values() {
+			changes[i][token] = value
+		}
+	}
+
+	// edit represents the text replacing the content between the offsets
+	type edit struct {
+		start, end int
+		text       string
+	}
+
+	var edits []edit
+
+	for i, values := range changes {
+		// variable to store the tokens missing from the entry
+		var missing strings.Builder
+
+		for _, token := range netrcTokens {
+			value, ok := values[token]
+			if !ok {
+				continue
+			}
+
+			loc, ok := entries[i].values[token]
+			if !ok {
+				fmt.Fprintf(&missing, " %s %s", token, value)
+
+				continue
+			}
+
+			edits = append(edits, edit{start: loc[0], end: loc[1], text: value})
+		}
+
+		if missing.Len() > 0 {
+			edits = append(edits, edit{start: entries[i].header, end: entries[i].header, text: missing.String()})
+		}
+	}
+
+	// add the new machines before the default entry since it must be last
+	insert := len(content)
+
+	for _, e := range entries {
+		if len(e.machine) == 0 {
+			insert = e.start
+
+			break
+		}
+	}
+
+	if len(added) > 0 {
+		text := renderNetrc(added)
+
+		// separate the new entries from an entry without a trailing newline
+		if insert == len(content) && len(content) > 0 && !strings.HasSuffix(content, "\n") {
+			text = "\n" + text
+		}
+
+		// separate the new entries from the default entry
+		if insert < len(content) {
+			text = strings.TrimPrefix(text, "\n") + "\n"
+		}
+
+		edits = append(edits, edit{start: insert, end: insert, text: text})
+	}
+
+	// apply the edits from the end so the offsets of the others are unchanged
+	slices.SortFunc(edits, func(a, b edit) int { return b.start - a.start })
+
+	for _, e := range edits {
+		content = content[:e.start] + e.text + content[e.end:]
+	}
+
+	return content
+}
+
+// renderNetrc is a helper function to return the .netrc
+// content for the entries with any default entry last.
+func renderNetrc(entries []*Netrc) string {
+	var (
+		b        strings.Builder
+		defaults *Netrc
+	)
+
+	for _, e := range entries {
+		// hold the default entry since it must follow every machine
+		if len(e.Machine) == 0 {
+			defaults = e
+
+			continue
+		}
+
+		fmt.Fprintf(&b, netrcFile, e.Machine, e.Login, e.Password)
+
+		if len(e.Account) > 0 {
+			fmt.Fprintf(&b, "account %s\n", e.Account)
+		}
+	}
+
+	if defaults != nil {
+		fmt.Fprintf(&b, netrcDefault, defaults.Login, defaults.Password)
+	}
+
+	return b.String()
+}
+
+// values is a helper function to return the
+// values provided for the tokens of the entry.
+func (n *Netrc) values() map[string]string {
+	values := make(map[string]string)
+
+	for token, value := range map[string]string{"account": n.Account, "login": n.Login, "password": n.Password} {
+		if len(value) > 0 {
+			values[token] = value
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestTerraform_readNetrcs(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	files := map[string]string{
+		"/vela/secrets/terraform/netrc/artifactory": "machine artifactory.example.com login deployer password abc123\n",
+		"/vela/secrets/terraform/netrc/github":      "machine github.example.com\nlogin octocat\npassword xyz789\n",
+	}
+
+	for path, content := range files {
+		err := afero.WriteFile(appFS, path, []byte(content), 0600)
+		if err != nil {
+			t.Errorf("unable to create file %s: %v", path, err)
+		}
+	}
+
+	want := []*Netrc{
+		{Machine: "artifactory.example.com", Login: "deployer", Password: "abc123"},
+		{Machine: "github.example.com", Login: "octocat", Password: "xyz789"},
+	}
+
+	got, err := readNetrcs()
+	if err != nil {
+		t.Errorf("readNetrcs returned err: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readNetrcs is %v, want %v", got, want)
+	}
+}
+
+func TestTerraform_parseNetrc(t *testing.T) {
+	// setup types
+	content := `
+machine github.com
+  login octocat
+  password abc123
+macdef init
+machine ignored.example.com login macro password macro
+
+machine artifactory.example.com login deployer account ci password xyz789
+default login anonymous password guest
+`
+
+	want := []*Netrc{
+		{Machine: "github.com", Login: "octocat", Password: "abc123"},
+		{Machine: "artifactory.example.com", Login: "deployer", Password: "xyz789", Account: "ci"},
+	}
+
+	got := parseNetrc(content)
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNetrc is %v, want %v", got, want)
+	}
+}
+
+func TestTerraform_mergeNetrc(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name: "empty",
+			want: `
+machine github.com
+login octocat
+password updated
+
+machine artifactory.example.com
+login deployer
+password xyz789
+`,
+		},
+		{
+			name: "existing",
+			content: `machine github.com
+  login stale
+  account ci
+  password abc123
+macdef init
+cd /pub
+
+default login anonymous password guest
+`,
+			want: `machine github.com
+  login octocat
+  account ci
+  password updated
+macdef init
+cd /pub
+
+machine artifactory.example.com
+login deployer
+password xyz789
+
+default login anonymous password guest
+`,
+		},
+		{
+			name:    "missing tokens",
+			content: "machine github.com\nmachine artifactory.example.com login deployer password abc123",
+			want:    "machine github.com login octocat password updated\nmachine artifactory.example.com login deployer password xyz789",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := mergeNetrc(test.content,
+				&Netrc{Machine: "github.com", Login: "octocat", Password: "updated"},
+				&Netrc{Machine: "ghe.example.com"},
+				&Netrc{Machine: "artifactory.example.com", Login: "deployer", Password: "xyz789"},
+			)
+
+			if got != test.want {
+				t.Errorf("mergeNetrc is %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTerraform_renderNetrc(t *testing.T) {
+	// setup types
+	entries := []*Netrc{
+		{Login: "anonymous", Password: "guest"},
+		{Machine: "github.com", Login: "octocat", Password: "abc123"},
+	}
+
+	want := `
+machine github.com
+login octocat
+password abc123
+
+default
+login anonymous
+password guest
+`
+
+	got := renderNetrc(entries)
+
+	if got != want {
+		t.Errorf("renderNetrc is %q, want %q", got, want)
+	}
+}
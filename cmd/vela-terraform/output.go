@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const outputAction = "output"
+
+// invalidEnvKey represents the regexp to match the
+// characters of an output name not allowed in an env key.
+var invalidEnvKey = regexp.MustCompile(`[^A-Z0-9_]`)
+
+type (
+	// Output represents the plugin configuration for output information.
+	Output struct {
+		// terraform directory to read outputs from
+		Directory string
+		// path to the env file to write non-sensitive outputs to i.e. "$VELA_OUTPUTS"
+		EnvFile string
+		// path to the env file to write sensitive outputs to i.e. "$VELA_MASKED_OUTPUTS"
+		MaskedEnvFile string
+		Version       *semver.Version
+	}
+
+	// outputValue represents a single output from "terraform output -json".
+	outputValue struct {
+		// if set, the output is marked as sensitive in the configuration
+		Sensitive bool `json:"sensitive"`
+		// raw JSON value of the output
+		Value json.RawMessage `json:"value"`
+	}
+)
+
+// Command formats and outputs the Output command from
+// the provided configuration to read outputs from state.
+func (o *Output) Command(ctx context.Context) *exec.Cmd {
+	logrus.Trace("creating terraform output command from plugin configuration")
+
+	// global Variables
+	var globalFlags []string
+
+	// check if Directory is provided and terraform version supports chdir
+	if o.Directory != "." && SupportsChdir(o.Version) {
+		globalFlags = append(globalFlags, fmt.Sprintf("-chdir=%s", o.Directory))
+	}
+
+	globalFlags = append(globalFlags, outputAction, "-json")
+
+	//nolint:gosec // ignore G204
+	cmd := exec.CommandContext(ctx, terraformBin, globalFlags...)
+
+	// check if Directory is provided and terraform version doesn't support chdir
+	if o.Directory != "." && !SupportsChdir(o.Version) {
+		// output has no directory argument so run the command from it
+		cmd.Dir = o.Directory
+	}
+
+	return cmd
+}
+
+// Exec formats and runs the commands for exporting Terraform outputs.
+func (o *Output) Exec(ctx context.Context) error {
+	logrus.Trace("running output with provided configuration")
+
+	// create the output command for the file
+	cmd := o.Command(ctx)
+
+	// run the output command and capture the values
+	out, err := outputCmd(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	outputs := make(map[string]*outputValue)
+
+	err = json.Unmarshal(out, &outputs)
+	if err != nil {
+		return fmt.Errorf("failed to parse terraform outputs: %w", err)
+	}
+
+	return o.Write(stdout(ctx), outputs)
+}
+
+// Write exports the provided outputs to the env files
+// and logs each exported output to w.
+func (o *Output) Write(w io.Writer, outputs map[string]*outputValue) error {
+	logrus.Trace("writing terraform outputs")
+
+	// variables to store the lines for each env file
+	var plain, masked []string
+
+	// variable to store the output name for each env key
+	keys := make(map[string]string)
+
+	for _, name := range slices.Sorted(maps.Keys(outputs)) {
+		output := outputs[name]
+
+		key := envKey(name)
+
+		// verify the output won't overwrite another output with the same key
+		if other, ok := keys[key]; ok {
+			return fmt.Errorf("outputs %s and %s are both exported as %s", other, name, key)
+		}
+
+		keys[key] = name
+
+		value, err := output.EnvValue()
+		if err != nil {
+			return fmt.Errorf("failed to format output %s: %w", name, err)
+		}
+
+		// check if the output is sensitive
+		if output.Sensitive {
+			// verify sensitive outputs won't be written in plain text
+			if len(o.MaskedEnvFile) == 0 {
+				return fmt.Errorf("no masked env file provided for sensitive output %s", name)
+			}
+
+			masked = append(masked, fmt.Sprintf("%s=%s", key, value))
+
+			fmt.Fprintf(w, "%s=***\n", key)
+
+			continue
+		}
+
+		plain = append(plain, fmt.Sprintf("%s=%s", key, value))
+
+		fmt.Fprintf(w, "%s=%s\n", key, value)
+	}
+
+	err := appendLines(o.EnvFile, plain)
+	if err != nil {
+		return err
+	}
+
+	return appendLines(o.MaskedEnvFile, masked)
+}
+
+// Validate verifies the Output is properly configured.
+func (o *Output) Validate() error {
+	logrus.Trace("validating output plugin configuration")
+
+	// verify EnvFile is provided
+	if len(o.EnvFile) == 0 {
+		return fmt.Errorf("no output env file provided")
+	}
+
+	return nil
+}
+
+// envKey is a helper function to convert the output name to an env
+// key by upper-casing it and replacing any invalid characters with "_".
+func envKey(name string) string {
+	return invalidEnvKey.ReplaceAllString(strings.ToUpper(name), "_")
+}
+
+// EnvValue returns the output value formatted for an env file.
+func (v *outputValue) EnvValue() (string, error) {
+	var s string
+
+	// check if the value is a string
+	err := json.Unmarshal(v.Value, &s)
+	if err != nil {
+		// use the compact JSON for lists, maps, objects and numbers
+		b := new(bytes.Buffer)
+
+		err = json.Compact(b, v.Value)
+		if err != nil {
+			return "", err
+		}
+
+		return b.String(), nil
+	}
+
+	// check if the string needs to be quoted to remain on a single line
+	if strings.ContainsAny(s, "\r\n") {
+		return strconv.Quote(s), nil
+	}
+
+	return s, nil
+}
+
+// appendLines is a helper function to append
+// the provided lines to the file at path.
+func appendLines(path string, lines []string) error {
+	// check if there are any lines to write
+	if len(lines) == 0 {
+		return nil
+	}
+
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	f, err := a.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(strings.Join(lines, "\n") + "\n")
+
+	return err
+}
@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/spf13/afero"
+)
+
+func TestTerraform_Output_Command(t *testing.T) {
+	v, _ := semver.NewVersion("1.0.0")
+	// setup types
+	o := &Output{
+		Directory: "foobar/",
+		Version:   v,
+	}
+
+	//nolint:gosec // ignore G204
+	want := exec.CommandContext(
+		t.Context(),
+		terraformBin,
+		"-chdir=foobar/",
+		outputAction,
+		"-json",
+	)
+
+	got := o.Command(t.Context())
+	if got.Path != want.Path {
+		t.Errorf("Command path is %v, want %v", got.Path, want.Path)
+	}
+
+	if !slices.Equal(got.Args, want.Args) {
+		t.Errorf("Command args is %v, want %v", got.Args, want.Args)
+	}
+}
+
+func TestTerraform_Output_Command_tf13(t *testing.T) {
+	v, _ := semver.NewVersion("0.13.0")
+	// setup types
+	o := &Output{
+		Directory: "foobar/",
+		Version:   v,
+	}
+
+	//nolint:gosec // ignore G204
+	want := exec.CommandContext(
+		t.Context(),
+		terraformBin,
+		outputAction,
+		"-json",
+	)
+
+	got := o.Command(t.Context())
+	if !slices.Equal(got.Args, want.Args) {
+		t.Errorf("Command args is %v, want %v", got.Args, want.Args)
+	}
+
+	if got.Dir != o.Directory {
+		t.Errorf("Command dir is %v, want %v", got.Dir, o.Directory)
+	}
+}
+
+func TestTerraform_Output_Exec_Error(t *testing.T) {
+	v, _ := semver.NewVersion("1.0.0")
+	// setup types
+	o := &Output{
+		Directory: "foobar/",
+		EnvFile:   "/vela/outputs/.env",
+		Version:   v,
+	}
+
+	err := o.Exec(t.Context())
+	if err == nil {
+		t.Errorf("Exec should have returned err")
+	}
+}
+
+func TestTerraform_Output_Write(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	// setup types
+	o := &Output{
+		Directory:     "foobar/",
+		EnvFile:       "/vela/outputs/.env",
+		MaskedEnvFile: "/vela/outputs/masked.env",
+	}
+
+	err := a.WriteFile(o.EnvFile, []byte("EXISTING=true\n"), 0600)
+	if err != nil {
+		t.Errorf("Unable to write file: %v", err)
+	}
+
+	outputs := make(map[string]*outputValue)
+
+	err = json.Unmarshal([]byte(`{
+		"bucket-name": {"sensitive": false, "type": "string", "value": "my-bucket"},
+		"subnet_ids": {"sensitive": false, "type": ["list", "string"], "value": ["a", "b"]},
+		"db_password": {"sensitive": true, "type": "string", "value": "hunter2"}
+	}`), &outputs)
+	if err != nil {
+		t.Errorf("Unable to unmarshal outputs: %v", err)
+	}
+
+	w := new(bytes.Buffer)
+
+	err = o.Write(w, outputs)
+	if err != nil {
+		t.Errorf("Write returned err: %v", err)
+	}
+
+	if strings.Contains(w.String(), "hunter2") {
+		t.Errorf("Write output %q should not contain sensitive values", w.String())
+	}
+
+	got, _ := a.ReadFile(o.EnvFile)
+
+	want := "EXISTING=true\nBUCKET_NAME=my-bucket\nSUBNET_IDS=[\"a\",\"b\"]\n"
+	if string(got) != want {
+		t.Errorf("env file is %q, want %q", got, want)
+	}
+
+	got, _ = a.ReadFile(o.MaskedEnvFile)
+
+	want = "DB_PASSWORD=hunter2\n"
+	if string(got) != want {
+		t.Errorf("masked env file is %q, want %q", got, want)
+	}
+}
+
+func TestTerraform_Output_Write_NoMaskedFile(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	// setup types
+	o := &Output{
+		Directory: "foobar/",
+		EnvFile:   "/vela/outputs/.env",
+	}
+
+	outputs := map[string]*outputValue{
+		"db_password": {Sensitive: true, Value: json.RawMessage(`"hunter2"`)},
+	}
+
+	err := o.Write(new(bytes.Buffer), outputs)
+	if err == nil {
+		t.Errorf("Write should have returned err")
+	}
+}
+
+func TestTerraform_Output_Write_Collision(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	// setup types
+	o := &Output{
+		Directory: "foobar/",
+		EnvFile:   "/vela/outputs/.env",
+	}
+
+	outputs := map[string]*outputValue{
+		"bucket-name": {Value: json.RawMessage(`"foo"`)},
+		"bucket_name": {Value: json.RawMessage(`"bar"`)},
+	}
+
+	err := o.Write(new(bytes.Buffer), outputs)
+	if err == nil {
+		t.Errorf("Write should have returned err")
+	}
+
+	ok, _ := afero.Exists(appFS, o.EnvFile)
+	if ok {
+		t.Errorf("Write should not have created %s", o.EnvFile)
+	}
+}
+
+func TestTerraform_outputValue_EnvValue(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: `"foo"`, want: "foo"},
+		{value: `"foo\nbar"`, want: `"foo\nbar"`},
+		{value: `3`, want: "3"},
+		{value: `true`, want: "true"},
+		{value: `{"a": 1, "b": [1, 2]}`, want: `{"a":1,"b":[1,2]}`},
+	}
+
+	// run tests
+	for _, test := range tests {
+		v := &outputValue{Value: json.RawMessage(test.value)}
+
+		got, err := v.EnvValue()
+		if err != nil {
+			t.Errorf("EnvValue returned err: %v", err)
+		}
+
+		if got != test.want {
+			t.Errorf("EnvValue is %v, want %v", got, test.want)
+		}
+	}
+}
+
+func TestTerraform_Output_Validate(t *testing.T) {
+	// setup types
+	o := &Output{Directory: "foobar/", EnvFile: "/vela/outputs/.env"}
+
+	err := o.Validate()
+	if err != nil {
+		t.Errorf("Validate returned err: %v", err)
+	}
+
+	o.EnvFile = ""
+
+	err = o.Validate()
+	if err == nil {
+		t.Errorf("Validate should have returned err")
+	}
+}
@@ -4,8 +4,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -15,6 +17,12 @@ import (
 
 const planAction = "plan"
 
+var (
+	// ErrPlanStateConflict defines the error type when the
+	// same path is provided for a plan file and a state file.
+	ErrPlanStateConflict = errors.New("plan file and state file must use different paths")
+)
+
 // Plan represents the plugin configuration for plan information.
 type Plan struct {
 	// If set, a plan will be generated to destroy all resources managed by the given configuration and state. i.e. "-destroy"
@@ -23,6 +31,8 @@ type Plan struct {
 	DetailedExitCode bool
 	// terraform file or directory to plan
 	Directory string
+	// thresholds the plan must not exceed
+	Guardrails *Guardrails
 	// ask for input for variables if not directly set. i.e. "-input=true"
 	Input bool
 	// the state file when locking is supported. i.e. -lock=true
@@ -39,10 +49,12 @@ type Plan struct {
 	Parallelism int
 	// update state prior to checking for differences. i.e. "-refresh=true"
 	Refresh bool
+	// resources to replace, requires terraform 0.15.2 or later. i.e. "-replace=resource"
+	Replace []string
 	// path to read and save state (unless state-out is specified). i.e. "-state=path"
 	State string
-	// resource to target. i.e. "-target=resource"
-	Target string
+	// resources to target. i.e. "-target=resource"
+	Targets []string
 	// set a variable in the Terraform configuration. i.e. "-var 'foo=bar'"
 	Vars []string
 	// set variables in the Terraform configuration from a file. i.e. "-var-file=foo"
@@ -96,8 +108,8 @@ func (p *Plan) Command(ctx context.Context) *exec.Cmd {
 		flags = append(flags, fmt.Sprintf("-lock-timeout=%s", p.LockTimeout))
 	}
 
-	// check if ModuleDepth is provided
-	if p.ModuleDepth > 0 {
+	// check if ModuleDepth is provided and supported by the version
+	if p.ModuleDepth > 0 && supported(_moduleDepth, p.Version) {
 		// add flag for ModuleDepth from provided plan command
 		flags = append(flags, fmt.Sprintf("-module-depth=%d", p.ModuleDepth))
 	}
@@ -111,7 +123,7 @@ func (p *Plan) Command(ctx context.Context) *exec.Cmd {
 	// check if Out is provided
 	if len(p.Out) > 0 {
 		// add flag for Out from provided plan command
-		flags = append(flags, fmt.Sprintf("-out=%s", p.Out))
+		flags = append(flags, fmt.Sprintf("-out=%s", p.outPath()))
 	}
 
 	// check if Parallelism is provided
@@ -132,10 +144,20 @@ func (p *Plan) Command(ctx context.Context) *exec.Cmd {
 		flags = append(flags, fmt.Sprintf("-state=%s", p.State))
 	}
 
-	// check if Target is provided
-	if len(p.Target) > 0 {
-		// add flag for Target from provided plan command
-		flags = append(flags, fmt.Sprintf("-target=%s", p.Target))
+	// check if Replace is provided
+	if len(p.Replace) > 0 {
+		for _, r := range p.Replace {
+			// add flag for Replace from provided plan command
+			flags = append(flags, fmt.Sprintf("-replace=%s", r))
+		}
+	}
+
+	// check if Targets is provided
+	if len(p.Targets) > 0 {
+		for _, t := range p.Targets {
+			// add flag for Targets from provided plan command
+			flags = append(flags, fmt.Sprintf("-target=%s", t))
+		}
 	}
 
 	// check if VarFiles is provided
@@ -162,7 +184,7 @@ func (p *Plan) Command(ctx context.Context) *exec.Cmd {
 	globalFlags = append(globalFlags, planAction)
 
 	//nolint:gosec // ignore G204
-	return exec.CommandContext(ctx, _terraform, append(globalFlags, flags...)...)
+	return exec.CommandContext(ctx, terraformBin, append(globalFlags, flags...)...)
 }
 
 // Exec formats and runs the commands for planning Terraform.
@@ -173,12 +195,54 @@ func (p *Plan) Exec(ctx context.Context) error {
 	cmd := p.Command(ctx)
 
 	// run the plan command for the file
-	err := execCmd(cmd)
+	err := execCmd(ctx, cmd)
+
+	// variable to store the exit status of a plan with changes present
+	var changes error
+
+	// terraform exits with status 2 for a successful plan with changes present
+	exitErr := new(exec.ExitError)
+	if p.DetailedExitCode && errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+		changes, err = err, nil
+	}
+
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// check if Out is provided
+	if len(p.Out) == 0 {
+		logrus.Debug("no plan out provided, skipping plan summary")
+
+		return changes
+	}
+
+	// parse the plan file to summarize the changes
+	plan, err := readPlan(ctx, p.Directory, p.Version, p.Out)
+	if err != nil {
+		return err
+	}
+
+	plan.WriteSummary(stdout(ctx))
+
+	// verify the plan doesn't exceed the guardrails
+	err = p.Guardrails.Check(plan)
+	if err != nil {
+		return err
+	}
+
+	return changes
+}
+
+// outPath returns the path to write the plan
+// file as an argument for the plan command.
+func (p *Plan) outPath() string {
+	// check if Directory is provided and terraform version doesn't support chdir
+	if p.Directory != "." && !SupportsChdir(p.Version) {
+		return localPath(p.Directory, p.Out)
+	}
+
+	return p.Out
 }
 
 // Validate verifies the Delete is properly configured.
@@ -189,5 +253,39 @@ func (p *Plan) Validate() error {
 		logrus.Warn("terraform plan will run in current dir")
 	}
 
+	// verify the plan file won't overwrite the state file
+	if samePath(p.Out, p.State) {
+		return fmt.Errorf("%w: %s", ErrPlanStateConflict, p.Out)
+	}
+
+	// verify the guardrails have a plan file to check
+	if p.Guardrails.Enabled() && len(p.Out) == 0 {
+		return fmt.Errorf("%w: plan_out must be provided to plan with guardrails", ErrGuardrail)
+	}
+
+	// verify replace is used with a supported plan
+	if len(p.Replace) > 0 {
+		// check if the version supports replace
+		if !SupportsReplace(p.Version) {
+			return unsupported(_replace, p.Version)
+		}
+
+		// terraform rejects replace when planning to destroy
+		if p.Destroy {
+			return fmt.Errorf("%w: replace is not supported with destroy", ErrUnsupportedFlag)
+		}
+	}
+
 	return nil
 }
+
+// samePath is a helper function to check if two
+// provided paths refer to the same file.
+func samePath(a, b string) bool {
+	// check if either path is empty
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+
+	return filepath.Clean(a) == filepath.Clean(b)
+}
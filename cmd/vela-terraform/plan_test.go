@@ -3,9 +3,14 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,8 +32,9 @@ func TestTerraform_Plan_Command(t *testing.T) {
 		Out:              "/path/to/out.tf",
 		Parallelism:      1,
 		Refresh:          true,
+		Replace:          []string{"aws_instance.web"},
 		State:            "state.tf",
-		Target:           "target.tf",
+		Targets:          []string{"target1.tf", "target2.tf"},
 		Vars:             []string{"foo=bar", "bar=foo"},
 		VarFiles:         []string{"vars1.tf", "vars2.tf"},
 		Version:          v,
@@ -37,7 +43,7 @@ func TestTerraform_Plan_Command(t *testing.T) {
 	//nolint:gosec // ignore G204
 	want := exec.CommandContext(
 		t.Context(),
-		_terraform,
+		terraformBin,
 		fmt.Sprintf("-chdir=%s", p.Directory),
 		planAction,
 		"-destroy",
@@ -45,13 +51,14 @@ func TestTerraform_Plan_Command(t *testing.T) {
 		"-input=true",
 		"-lock=true",
 		fmt.Sprintf("-lock-timeout=%s", p.LockTimeout),
-		fmt.Sprintf("-module-depth=%d", p.ModuleDepth),
 		"-no-color",
 		fmt.Sprintf("-out=%s", p.Out),
 		fmt.Sprintf("-parallelism=%d", p.Parallelism),
 		"-refresh=true",
 		fmt.Sprintf("-state=%s", p.State),
-		fmt.Sprintf("-target=%s", p.Target),
+		fmt.Sprintf("-replace=%s", p.Replace[0]),
+		fmt.Sprintf("-target=%s", p.Targets[0]),
+		fmt.Sprintf("-target=%s", p.Targets[1]),
 		fmt.Sprintf("-var-file=%s", p.VarFiles[0]),
 		fmt.Sprintf("-var-file=%s", p.VarFiles[1]),
 		fmt.Sprintf("-var=%s", p.Vars[0]),
@@ -80,11 +87,11 @@ func TestTerraform_Plan_Command_tf13(t *testing.T) {
 		LockTimeout:      1 * time.Second,
 		ModuleDepth:      1,
 		NoColor:          true,
-		Out:              "/path/to/out.tf",
+		Out:              "out.tf",
 		Parallelism:      1,
 		Refresh:          true,
 		State:            "state.tf",
-		Target:           "target.tf",
+		Targets:          []string{"target1.tf", "target2.tf"},
 		Vars:             []string{"foo=bar", "bar=foo"},
 		VarFiles:         []string{"vars1.tf", "vars2.tf"},
 		Version:          v,
@@ -93,20 +100,20 @@ func TestTerraform_Plan_Command_tf13(t *testing.T) {
 	//nolint:gosec // ignore G204
 	want := exec.CommandContext(
 		t.Context(),
-		_terraform,
+		terraformBin,
 		planAction,
 		"-destroy",
 		"-detailed-exitcode",
 		"-input=true",
 		"-lock=true",
 		fmt.Sprintf("-lock-timeout=%s", p.LockTimeout),
-		fmt.Sprintf("-module-depth=%d", p.ModuleDepth),
 		"-no-color",
-		fmt.Sprintf("-out=%s", p.Out),
+		"-out=foobar/out.tf",
 		fmt.Sprintf("-parallelism=%d", p.Parallelism),
 		"-refresh=true",
 		fmt.Sprintf("-state=%s", p.State),
-		fmt.Sprintf("-target=%s", p.Target),
+		fmt.Sprintf("-target=%s", p.Targets[0]),
+		fmt.Sprintf("-target=%s", p.Targets[1]),
 		fmt.Sprintf("-var-file=%s", p.VarFiles[0]),
 		fmt.Sprintf("-var-file=%s", p.VarFiles[1]),
 		fmt.Sprintf("-var=%s", p.Vars[0]),
@@ -124,6 +131,34 @@ func TestTerraform_Plan_Command_tf13(t *testing.T) {
 	}
 }
 
+func TestTerraform_Plan_Command_tf11(t *testing.T) {
+	v, _ := semver.NewVersion("0.11.0")
+	// setup types
+	p := &Plan{
+		Directory:   "foobar/",
+		ModuleDepth: 1,
+		Version:     v,
+	}
+
+	//nolint:gosec // ignore G204
+	want := exec.CommandContext(
+		t.Context(),
+		terraformBin,
+		planAction,
+		fmt.Sprintf("-module-depth=%d", p.ModuleDepth),
+		fmt.Sprint(p.Directory),
+	)
+
+	got := p.Command(t.Context())
+	if got.Path != want.Path {
+		t.Errorf("Command path is %v, want %v", got.Path, want.Path)
+	}
+
+	if !slices.Equal(got.Args, want.Args) {
+		t.Errorf("Command args is %v, want %v", got.Args, want.Args)
+	}
+}
+
 func TestTerraform_Plan_Exec(t *testing.T) {
 	v, _ := semver.NewVersion("1.0.0")
 	// setup types
@@ -138,6 +173,130 @@ func TestTerraform_Plan_Exec(t *testing.T) {
 	}
 }
 
+func TestTerraform_Plan_Exec_DetailedExitCode(t *testing.T) {
+	// setup terraform which exits with changes present for the plan
+	dir := t.TempDir()
+
+	script := `#!/bin/sh
+case "$*" in
+  *show*) echo '{"resource_changes": [{"address": "null_resource.foo", "type": "null_resource", "change": {"actions": ["delete"]}}]}' ;;
+  *) exit 2 ;;
+esac
+`
+
+	err := os.WriteFile(filepath.Join(dir, "terraform"), []byte(script), 0755)
+	if err != nil {
+		t.Errorf("unable to create file: %v", err)
+	}
+
+	terraformBin = filepath.Join(dir, "terraform")
+	t.Cleanup(func() { terraformBin = _terraform })
+
+	v, _ := semver.NewVersion("1.0.0")
+
+	// setup tests
+	tests := []struct {
+		name       string
+		guardrails *Guardrails
+		want       int
+	}{
+		{
+			name:       "changes present",
+			guardrails: &Guardrails{MaxDestroy: -1, MaxChanges: -1},
+			want:       2,
+		},
+		{
+			name:       "guardrail exceeded",
+			guardrails: &Guardrails{MaxDestroy: 0, MaxChanges: -1},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := new(bytes.Buffer)
+
+			// setup types
+			p := &Plan{
+				DetailedExitCode: true,
+				Directory:        ".",
+				Guardrails:       test.guardrails,
+				Out:              "tfplan",
+				Version:          v,
+			}
+
+			err := p.Exec(withOutput(t.Context(), w))
+
+			exitErr := new(exec.ExitError)
+			if test.want > 0 && (!errors.As(err, &exitErr) || exitErr.ExitCode() != test.want) {
+				t.Errorf("Exec returned err %v, want exit status %d", err, test.want)
+			}
+
+			if test.want == 0 && !errors.Is(err, ErrGuardrail) {
+				t.Errorf("Exec returned err %v, want %v", err, ErrGuardrail)
+			}
+
+			if !strings.Contains(w.String(), "null_resource.foo") {
+				t.Errorf("Exec output is %q, want the plan summary", w.String())
+			}
+		})
+	}
+}
+
+func TestTerraform_Plan_Exec_tf13(t *testing.T) {
+	// setup terraform which writes the plan file relative to the current directory
+	dir := t.TempDir()
+
+	script := `#!/bin/sh
+for arg in "$@"; do
+  case "$arg" in
+    -out=*) touch "${arg#-out=}" ;;
+  esac
+done
+case "$1" in
+  show) [ -f "$3" ] && echo '{"resource_changes": []}' || exit 1 ;;
+esac
+`
+
+	err := os.WriteFile(filepath.Join(dir, "terraform"), []byte(script), 0755)
+	if err != nil {
+		t.Errorf("unable to create file: %v", err)
+	}
+
+	terraformBin = filepath.Join(dir, "terraform")
+	t.Cleanup(func() { terraformBin = _terraform })
+
+	// run terraform from outside of the directory
+	t.Chdir(dir)
+
+	err = os.Mkdir("foobar", 0755)
+	if err != nil {
+		t.Errorf("unable to create directory: %v", err)
+	}
+
+	v, _ := semver.NewVersion("0.13.0")
+
+	// setup types
+	p := &Plan{
+		Directory: "foobar",
+		Out:       "plan.out",
+		Version:   v,
+	}
+
+	err = p.Exec(withOutput(t.Context(), new(bytes.Buffer)))
+	if err != nil {
+		t.Errorf("Exec returned err: %v", err)
+	}
+
+	// verify the plan file is where apply reads it
+	a := &Apply{Directory: p.Directory, PlanFile: p.Out, Version: v}
+
+	_, err = os.Stat(a.planPath())
+	if err != nil {
+		t.Errorf("plan file should exist at %s: %v", a.planPath(), err)
+	}
+}
+
 func TestTerraform_Plan_Validate(t *testing.T) {
 	v, _ := semver.NewVersion("1.0.0")
 	// setup types
@@ -153,6 +312,9 @@ func TestTerraform_Plan_Validate(t *testing.T) {
 		{
 			plan: &Plan{Directory: "", Version: v},
 		},
+		{
+			plan: &Plan{Directory: "foobar/", Out: "plan.out", State: "terraform.tfstate", Version: v},
+		},
 	}
 
 	// run test
@@ -163,3 +325,70 @@ func TestTerraform_Plan_Validate(t *testing.T) {
 		}
 	}
 }
+
+func TestTerraform_Plan_Validate_StateConflict(t *testing.T) {
+	v, _ := semver.NewVersion("1.0.0")
+	// setup types
+	p := &Plan{
+		Directory: "foobar/",
+		Out:       "./terraform.tfstate",
+		State:     "terraform.tfstate",
+		Version:   v,
+	}
+
+	err := p.Validate()
+	if !errors.Is(err, ErrPlanStateConflict) {
+		t.Errorf("Validate returned err %v, want %v", err, ErrPlanStateConflict)
+	}
+}
+
+func TestTerraform_Plan_Validate_Guardrails(t *testing.T) {
+	v, _ := semver.NewVersion("1.0.0")
+	// setup types
+	p := &Plan{
+		Directory:  "foobar/",
+		Guardrails: &Guardrails{MaxDestroy: -1, MaxChanges: 10},
+		Version:    v,
+	}
+
+	err := p.Validate()
+	if !errors.Is(err, ErrGuardrail) {
+		t.Errorf("Validate returned err %v, want %v", err, ErrGuardrail)
+	}
+
+	p.Out = "plan.out"
+
+	err = p.Validate()
+	if err != nil {
+		t.Errorf("Validate returned err: %v", err)
+	}
+}
+
+func TestTerraform_Plan_Validate_Replace(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		plan *Plan
+		want error
+	}{
+		{
+			plan: &Plan{Directory: "foobar/", Replace: []string{"aws_instance.web"}, Version: semver.MustParse("0.15.2")},
+			want: nil,
+		},
+		{
+			plan: &Plan{Directory: "foobar/", Replace: []string{"aws_instance.web"}, Version: semver.MustParse("0.15.1")},
+			want: ErrUnsupportedFlag,
+		},
+		{
+			plan: &Plan{Destroy: true, Directory: "foobar/", Replace: []string{"aws_instance.web"}, Version: semver.MustParse("1.0.0")},
+			want: ErrUnsupportedFlag,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		err := test.plan.Validate()
+		if !errors.Is(err, test.want) {
+			t.Errorf("Validate returned err %v, want %v", err, test.want)
+		}
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
 
 	"github.com/sirupsen/logrus"
 )
@@ -15,18 +18,30 @@ type (
 	Plugin struct {
 		// Apply arguments loaded for the plugin
 		Apply *Apply
+		// Changes arguments loaded for the plugin
+		Changes *Changes
 		// config arguments loaded for the plugin
 		Config *Config
 		// Destroy arguments loaded for the plugin
 		Destroy *Destroy
+		// Directories arguments loaded for the plugin
+		Directories *Directories
+		// Environment arguments loaded for the plugin
+		Environment *Environment
 		// InitOptions arguments loaded for the plugin
 		Init *Init
 		// FMT arguments loaded for the plugin
 		FMT *FMT
+		// Output arguments loaded for the plugin
+		Output *Output
 		// Plan arguments loaded for the plugin
 		Plan *Plan
 		// Validation arguments loaded for the plugin
 		Validation *Validation
+		// Variables arguments loaded for the plugin
+		Variables *Variables
+		// Workspace arguments loaded for the plugin
+		Workspace *Workspace
 	}
 )
 
@@ -34,70 +49,336 @@ var (
 	// ErrInvalidAction defines the error type when the
 	// Action provided to the Plugin is unsupported.
 	ErrInvalidAction = errors.New("invalid action provided")
+
+	// ErrUnsupportedFlag defines the error type when a flag
+	// is unsupported by the terraform version or action.
+	ErrUnsupportedFlag = errors.New("unsupported flag provided")
 )
 
 // Exec formats and runs the commands for running Terraform commands.
 func (p *Plugin) Exec(ctx context.Context) error {
 	logrus.Debug("running plugin with provided configuration")
 
-	// write the .netrc file with Github credentials
-	err := p.Config.Write()
+	// capture the secrets to mask in the output
+	secrets, err := readSecrets()
+	if err != nil {
+		return err
+	}
+
+	m := new(Masker)
+
+	// check if netrc credentials were provided
+	if p.Config.Netrc != nil {
+		m.Add(p.Config.Netrc.Password)
+	}
+
+	for _, n := range p.Config.netrcs {
+		m.Add(n.Password)
+	}
+
+	m.Add(slices.Collect(maps.Values(p.Config.registryCredentials))...)
+
+	// check if SSH credentials were provided
+	if p.Config.SSH != nil {
+		m.Add(p.Config.SSH.Key)
+	}
+
+	m.Add(secrets...)
+
+	ctx = withMasker(ctx, m)
+
+	// remove the SSH key material once the plugin finishes
+	defer func() {
+		err := p.Config.Remove()
+		if err != nil {
+			logrus.Warnf("unable to remove SSH key files: %v", err)
+		}
+	}()
+
+	// write the .netrc, CLI config and SSH files with the credentials
+	err = p.Config.Write()
 	if err != nil {
 		return err
 	}
 
 	// output terraform version for troubleshooting
-	err = execCmd(versionCmd(ctx))
+	err = execCmd(ctx, versionCmd(ctx))
 	if err != nil {
 		return err
 	}
 
-	// initialize a new or existing Terraform working directory
-	err = p.Init.Exec(ctx)
+	// configure the terraform environment
+	err = p.Environment.Export()
 	if err != nil {
 		return err
 	}
 
-	// retrieve terraform modules for actions
-	err = execCmd(getCmd(ctx, p.Init.Directory))
+	// check if the plugin runs in a single directory
+	if len(p.Directories.Patterns) == 0 && !p.Changes.Only {
+		return p.execDirectory(ctx)
+	}
+
+	dirs := []string{p.Init.Directory}
+
+	// check if multiple directories were provided
+	if len(p.Directories.Patterns) > 0 {
+		dirs, err = p.Directories.Expand()
+		if err != nil {
+			return err
+		}
+	}
+
+	// check if only the changed directories should run
+	if p.Changes.Only {
+		dirs, err = p.Changes.Filter(ctx, dirs)
+		if err != nil {
+			return err
+		}
+
+		if len(dirs) == 0 {
+			logrus.Info("no directories affected by changes, skipping actions")
+
+			return nil
+		}
+	}
+
+	// check if a single directory was provided
+	if len(p.Directories.Patterns) == 0 {
+		return p.execDirectory(ctx)
+	}
+
+	// verify destroy is confirmed for every directory before any of them run
+	if slices.Contains(p.Config.Actions, destroyAction) || p.destroyPlan() {
+		err = p.confirmDestroy(dirs)
+		if err != nil {
+			return err
+		}
+	}
+
+	deps, err := p.Directories.Dependencies(dirs)
 	if err != nil {
 		return err
 	}
 
-	// configure the terraform environment
-	err = env()
+	// destroy the directories before the directories they depend on
+	if slices.Contains(p.Config.Actions, destroyAction) {
+		deps = reverseDependencies(deps)
+	}
+
+	// run the plugin in each directory
+	return p.Directories.Run(ctx, dirs, deps, func(ctx context.Context, dir string) error {
+		return p.forDirectory(dir).execDirectory(ctx)
+	})
+}
+
+// execDirectory initializes the working directory
+// and runs the actions with the plugin configuration.
+func (p *Plugin) execDirectory(ctx context.Context) error {
+	// capture the values of sensitive variables to mask in the output
+	values, err := p.sensitiveValues()
 	if err != nil {
 		return err
 	}
 
+	masker(ctx).Add(values...)
+
+	// variable to store if the working directory was restored
+	var restored bool
+
+	// reuse the working directory initialized by a previous step
+	if !slices.Contains(p.Config.Actions, initAction) {
+		restored, err = p.Init.Restore()
+		if err != nil {
+			return err
+		}
+	}
+
+	// check if the working directory needs to be initialized
+	if !restored {
+		// initialize a new or existing Terraform working directory
+		err = p.Init.Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		// retrieve terraform modules for actions
+		err = execCmd(ctx, getCmd(ctx, p.Init.Directory, p.Init.Version))
+		if err != nil {
+			return err
+		}
+	}
+
+	// select the workspace unless it is managed by the workspace action
+	if !slices.Contains(p.Config.Actions, workspaceAction) {
+		err = p.Workspace.Select(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	// remove the structured variables once the actions finish since they may contain secrets
+	defer func() {
+		err := p.Variables.Remove(p.Init.Directory)
+		if err != nil {
+			logrus.Warnf("unable to remove variables file: %v", err)
+		}
+	}()
+
+	// write the structured variables for the actions
+	file, err := p.Variables.Write(p.Init.Directory, p.Init.Version)
+	if err != nil {
+		return err
+	}
+
+	// check if the variables were written
+	if len(file) > 0 {
+		p.addVarFile(file)
+	}
+
+	// variable to store the result of each action
+	results := make([]*result, 0, len(p.Config.Actions))
+
+	// execute each action in order until one fails
+	for _, action := range p.Config.Actions {
+		// check if a previous action failed
+		if err != nil {
+			results = append(results, &result{Name: action, Skipped: true})
+
+			continue
+		}
+
+		err = p.execAction(ctx, action)
+
+		results = append(results, &result{Name: action, Err: err})
+	}
+
+	// output a summary when multiple actions were requested
+	if len(results) > 1 {
+		writeSummary(stdout(ctx), "action", results)
+	}
+
+	return err
+}
+
+// forDirectory returns a copy of the plugin
+// configured to run in the provided directory.
+func (p *Plugin) forDirectory(dir string) *Plugin {
+	apply := *p.Apply
+	apply.Directory = dir
+
+	destroy := *p.Destroy
+	destroy.Directory = dir
+	destroy.perDirectory = true
+
+	apply.Destroy = &destroy
+
+	format := *p.FMT
+	format.Directory = dir
+
+	initialize := *p.Init
+	initialize.Directory = dir
+
+	// keep the init archive for each directory inside of it
+	if len(initialize.Archive) > 0 && !filepath.IsAbs(initialize.Archive) {
+		initialize.Archive = filepath.Join(dir, initialize.Archive)
+	}
+
+	output := *p.Output
+	output.Directory = dir
+
+	plan := *p.Plan
+	plan.Directory = dir
+
+	validation := *p.Validation
+	validation.Directory = dir
+
+	workspace := *p.Workspace
+	workspace.Directory = dir
+
+	return &Plugin{
+		Apply:       &apply,
+		Changes:     p.Changes,
+		Config:      p.Config,
+		Destroy:     &destroy,
+		Directories: p.Directories,
+		Environment: p.Environment,
+		Init:        &initialize,
+		FMT:         &format,
+		Output:      &output,
+		Plan:        &plan,
+		Validation:  &validation,
+		Variables:   p.Variables,
+		Workspace:   &workspace,
+	}
+}
+
+// confirmDestroy verifies destroy is confirmed for each of the directories.
+func (p *Plugin) confirmDestroy(dirs []string) error {
+	var errs []error
+
+	for _, dir := range dirs {
+		d := p.forDirectory(dir).Destroy
+
+		// a destroy plan is always confirmed since the saved plan is applied without approval
+		if p.destroyPlan() {
+			errs = append(errs, d.confirmed())
+
+			continue
+		}
+
+		errs = append(errs, d.Confirmed())
+	}
+
+	return errors.Join(errs...)
+}
+
+// destroyPlan is a helper function to check if
+// the plan action creates a plan to destroy resources.
+func (p *Plugin) destroyPlan() bool {
+	return p.Plan.Destroy && slices.Contains(p.Config.Actions, planAction)
+}
+
+// addVarFile adds the var file to the actions accepting variables.
+func (p *Plugin) addVarFile(file string) {
+	// clip each slice so copies of the plugin don't share the appended file
+	p.Apply.VarFiles = append(slices.Clip(p.Apply.VarFiles), file)
+	p.Destroy.VarFiles = append(slices.Clip(p.Destroy.VarFiles), file)
+	p.Plan.VarFiles = append(slices.Clip(p.Plan.VarFiles), file)
+	p.Validation.VarFiles = append(slices.Clip(p.Validation.VarFiles), file)
+}
+
+// execAction runs the provided action with the plugin configuration.
+func (p *Plugin) execAction(ctx context.Context, action string) error {
+	logrus.Debugf("running %s action", action)
+
 	// execute action specific configuration
-	switch p.Config.Action {
+	switch action {
 	case applyAction:
 		// execute apply action
 		return p.Apply.Exec(ctx)
 	case destroyAction:
 		// execute destroy action
 		return p.Destroy.Exec(ctx)
+	case initAction:
+		// package the initialized working directory
+		return p.Init.Package()
 	case fmtAction:
 		// execute fmt action
 		return p.FMT.Exec(ctx)
+	case outputAction:
+		// execute output action
+		return p.Output.Exec(ctx)
 	case planAction:
 		// execute plan action
 		return p.Plan.Exec(ctx)
 	case validationAction:
 		// execute validate action
 		return p.Validation.Exec(ctx)
+	case workspaceAction:
+		// execute workspace action
+		return p.Workspace.Exec(ctx)
 	default:
-		return fmt.Errorf(
-			"%w: %s (Valid actions: %s, %s, %s, %s, %s)",
-			ErrInvalidAction,
-			p.Config.Action,
-			applyAction,
-			destroyAction,
-			fmtAction,
-			planAction,
-			validationAction,
-		)
+		return invalidAction(action)
 	}
 }
 
@@ -105,8 +386,26 @@ func (p *Plugin) Exec(ctx context.Context) error {
 func (p *Plugin) Validate() error {
 	logrus.Debug("validating plugin configuration")
 
+	// unmarshal the registry credentials
+	err := p.Config.Unmarshal()
+	if err != nil {
+		return err
+	}
+
 	// validate config configuration
-	err := p.Config.Validate()
+	err = p.Config.Validate()
+	if err != nil {
+		return err
+	}
+
+	// validate directories configuration
+	err = p.Directories.Validate()
+	if err != nil {
+		return err
+	}
+
+	// validate changes configuration
+	err = p.Changes.Validate()
 	if err != nil {
 		return err
 	}
@@ -124,37 +423,116 @@ func (p *Plugin) Validate() error {
 		return err
 	}
 
+	// when user maps environment variables
+	// unmarshal them for the terraform variables
+	err = p.Environment.Unmarshal()
+	if err != nil {
+		return err
+	}
+
+	// when user adds structured variables
+	// unmarshal them for the var file
+	err = p.Variables.Unmarshal()
+	if err != nil {
+		return err
+	}
+
+	// verify a workspace is provided when it should be created
+	if p.Workspace.Create && len(p.Workspace.Name) == 0 {
+		return fmt.Errorf("%w: workspace is required for create_workspace", ErrNoWorkspace)
+	}
+
+	// capture the workspace and state key used to confirm destroy
+	p.Destroy.BackendKey = p.Init.InitOptions.BackendKey()
+	p.Destroy.Workspace = p.Workspace.Name
+
+	// apply the destroy safeguards to saved plans which only destroy resources
+	p.Apply.Destroy = p.Destroy
+
+	// verify the plan file won't overwrite a state file written by apply or destroy
+	if samePath(p.Plan.Out, p.Apply.StateOut) || samePath(p.Plan.Out, p.Destroy.StateOut) {
+		return fmt.Errorf("%w: %s", ErrPlanStateConflict, p.Plan.Out)
+	}
+
+	// validate each action provided
+	for _, action := range p.Config.Actions {
+		err = p.validateAction(action)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateAction verifies the provided action is properly configured.
+func (p *Plugin) validateAction(action string) error {
 	// validate action specific configuration
-	switch p.Config.Action {
+	switch action {
 	case applyAction:
 		// validate apply action
 		return p.Apply.Validate()
 	case destroyAction:
+		// check if destroy is confirmed for each directory once they are expanded
+		if len(p.Directories.Patterns) > 0 {
+			return p.Destroy.Allowed()
+		}
+
 		// validate destroy action
 		return p.Destroy.Validate()
 	case initAction:
-		// validate fmt action
+		// validate init action
 		return p.Init.Validate()
 	case fmtAction:
 		// validate fmt action
 		return p.FMT.Validate()
+	case outputAction:
+		// validate output action
+		return p.Output.Validate()
 	case planAction:
 		// validate plan action
-		return p.Plan.Validate()
+		err := p.Plan.Validate()
+		if err != nil {
+			return err
+		}
+
+		// check if the plan destroys resources
+		if !p.Plan.Destroy {
+			return nil
+		}
+
+		// check if destroy is confirmed for each directory once they are expanded
+		if len(p.Directories.Patterns) > 0 {
+			return p.Destroy.Protected()
+		}
+
+		// verify the destroy plan passes the destroy safeguards before it can be applied
+		return p.Destroy.Planned()
 	case validationAction:
 		// validate validate action
 		return p.Validation.Validate()
+	case workspaceAction:
+		// validate workspace action
+		return p.Workspace.Validate()
 	default:
-		return fmt.Errorf(
-			"%w: %s (Valid actions: %s, %s, %s, %s, %s, %s)",
-			ErrInvalidAction,
-			p.Config.Action,
-			applyAction,
-			destroyAction,
-			initAction,
-			fmtAction,
-			planAction,
-			validationAction,
-		)
+		return invalidAction(action)
 	}
 }
+
+// invalidAction is a helper function to create
+// the error for an unsupported action.
+func invalidAction(action string) error {
+	return fmt.Errorf(
+		"%w: %s (Valid actions: %s, %s, %s, %s, %s, %s, %s, %s)",
+		ErrInvalidAction,
+		action,
+		applyAction,
+		destroyAction,
+		initAction,
+		fmtAction,
+		outputAction,
+		planAction,
+		validationAction,
+		workspaceAction,
+	)
+}
@@ -3,10 +3,33 @@
 package main
 
 import (
+	"errors"
+	"slices"
 	"testing"
 	"time"
+
+	"github.com/spf13/afero"
 )
 
+func TestTerraform_Plugin_Exec_NoCredentials(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	// setup terraform which doesn't exist so the plugin stops after the credentials
+	terraformBin = "/nonexistent/terraform"
+	t.Cleanup(func() { terraformBin = _terraform })
+
+	// setup types
+	p := &Plugin{
+		Config: &Config{Actions: []string{"validate"}},
+	}
+
+	err := p.Exec(t.Context())
+	if err == nil {
+		t.Errorf("Exec should have returned err")
+	}
+}
+
 func TestTerraform_Plugin_Validate(t *testing.T) {
 	// setup tests
 	tests := []struct {
@@ -27,19 +50,23 @@ func TestTerraform_Plugin_Validate(t *testing.T) {
 					Refresh:     true,
 					State:       "state.tf",
 					StateOut:    "stateout.tf",
-					Target:      "target.tf",
+					Targets:     []string{"target.tf"},
 					Vars:        []string{"foo=bar", "bar=foo"},
 					VarFiles:    []string{"vars1.tf", "vars2.tf"},
 				},
 				Config: &Config{
-					Action: "apply",
+					Actions: []string{"apply"},
 					Netrc: &Netrc{
 						Machine:  "machine.example.com",
 						Login:    "octocat",
 						Password: "foobar",
 					},
 				},
-				Destroy: &Destroy{},
+				Destroy:     &Destroy{},
+				Changes:     &Changes{},
+				Directories: &Directories{Concurrency: 1},
+				Environment: &Environment{},
+				Variables:   &Variables{},
 				Init: &Init{
 					Directory: "foobar/",
 					InitOptions: &InitOptions{
@@ -62,6 +89,7 @@ func TestTerraform_Plugin_Validate(t *testing.T) {
 				FMT:        &FMT{},
 				Plan:       &Plan{},
 				Validation: &Validation{},
+				Workspace:  &Workspace{},
 			},
 			want: nil,
 		},
@@ -69,7 +97,7 @@ func TestTerraform_Plugin_Validate(t *testing.T) {
 			plugin: &Plugin{
 				Apply: &Apply{},
 				Config: &Config{
-					Action: "apply",
+					Actions: []string{"destroy"},
 					Netrc: &Netrc{
 						Machine:  "machine.example.com",
 						Login:    "octocat",
@@ -79,6 +107,7 @@ func TestTerraform_Plugin_Validate(t *testing.T) {
 				Destroy: &Destroy{
 					AutoApprove: true,
 					Backup:      "backup/",
+					Confirm:     []string{"foobar"},
 					Directory:   "foobar/",
 					Lock:        true,
 					LockTimeout: 1 * time.Second,
@@ -87,10 +116,14 @@ func TestTerraform_Plugin_Validate(t *testing.T) {
 					Refresh:     true,
 					State:       "state.tf",
 					StateOut:    "stateout.tf",
-					Target:      "target.tf",
+					Targets:     []string{"target.tf"},
 					Vars:        []string{"foo=bar", "bar=foo"},
 					VarFiles:    []string{"vars1.tf", "vars2.tf"},
 				},
+				Changes:     &Changes{},
+				Directories: &Directories{Concurrency: 1},
+				Environment: &Environment{},
+				Variables:   &Variables{},
 				Init: &Init{
 					Directory: "foobar/",
 					InitOptions: &InitOptions{
@@ -113,6 +146,7 @@ func TestTerraform_Plugin_Validate(t *testing.T) {
 				FMT:        &FMT{},
 				Plan:       &Plan{},
 				Validation: &Validation{},
+				Workspace:  &Workspace{},
 			},
 			want: nil,
 		},
@@ -120,14 +154,18 @@ func TestTerraform_Plugin_Validate(t *testing.T) {
 			plugin: &Plugin{
 				Apply: &Apply{},
 				Config: &Config{
-					Action: "apply",
+					Actions: []string{"fmt"},
 					Netrc: &Netrc{
 						Machine:  "machine.example.com",
 						Login:    "octocat",
 						Password: "foobar",
 					},
 				},
-				Destroy: &Destroy{},
+				Destroy:     &Destroy{},
+				Changes:     &Changes{},
+				Directories: &Directories{Concurrency: 1},
+				Environment: &Environment{},
+				Variables:   &Variables{},
 				Init: &Init{
 					Directory: "foobar/",
 					InitOptions: &InitOptions{
@@ -156,6 +194,7 @@ func TestTerraform_Plugin_Validate(t *testing.T) {
 				},
 				Plan:       &Plan{},
 				Validation: &Validation{},
+				Workspace:  &Workspace{},
 			},
 			want: nil,
 		},
@@ -163,14 +202,18 @@ func TestTerraform_Plugin_Validate(t *testing.T) {
 			plugin: &Plugin{
 				Apply: &Apply{},
 				Config: &Config{
-					Action: "apply",
+					Actions: []string{"plan"},
 					Netrc: &Netrc{
 						Machine:  "machine.example.com",
 						Login:    "octocat",
 						Password: "foobar",
 					},
 				},
-				Destroy: &Destroy{},
+				Destroy:     &Destroy{Confirm: []string{"foobar"}, Directory: "foobar/"},
+				Changes:     &Changes{},
+				Directories: &Directories{Concurrency: 1},
+				Environment: &Environment{},
+				Variables:   &Variables{},
 				Init: &Init{
 					Directory: "foobar/",
 					InitOptions: &InitOptions{
@@ -204,11 +247,12 @@ func TestTerraform_Plugin_Validate(t *testing.T) {
 					Parallelism:      1,
 					Refresh:          true,
 					State:            "state.tf",
-					Target:           "target.tf",
+					Targets:          []string{"target.tf"},
 					Vars:             []string{"foo=bar", "bar=foo"},
 					VarFiles:         []string{"vars1.tf", "vars2.tf"},
 				},
 				Validation: &Validation{},
+				Workspace:  &Workspace{},
 			},
 			want: nil,
 		},
@@ -216,14 +260,18 @@ func TestTerraform_Plugin_Validate(t *testing.T) {
 			plugin: &Plugin{
 				Apply: &Apply{},
 				Config: &Config{
-					Action: "apply",
+					Actions: []string{"validate"},
 					Netrc: &Netrc{
 						Machine:  "machine.example.com",
 						Login:    "octocat",
 						Password: "foobar",
 					},
 				},
-				Destroy: &Destroy{},
+				Destroy:     &Destroy{},
+				Changes:     &Changes{},
+				Directories: &Directories{Concurrency: 1},
+				Environment: &Environment{},
+				Variables:   &Variables{},
 				Init: &Init{
 					Directory: "foobar/",
 					InitOptions: &InitOptions{
@@ -252,6 +300,7 @@ func TestTerraform_Plugin_Validate(t *testing.T) {
 					Vars:           []string{"foo=bar", "bar=foo"},
 					VarFiles:       []string{"vars1.tf", "vars2.tf"},
 				},
+				Workspace: &Workspace{},
 			},
 			want: nil,
 		},
@@ -265,3 +314,305 @@ func TestTerraform_Plugin_Validate(t *testing.T) {
 		}
 	}
 }
+
+func TestTerraform_Plugin_Validate_Actions(t *testing.T) {
+	// setup types
+	p := &Plugin{
+		Apply: &Apply{Directory: "foobar/"},
+		Config: &Config{
+			Actions: []string{"init", "fmt", "validate", "plan"},
+			Netrc:   &Netrc{},
+		},
+		Destroy:     &Destroy{},
+		Changes:     &Changes{},
+		Directories: &Directories{Concurrency: 1},
+		Environment: &Environment{},
+		Variables:   &Variables{},
+		Init:        &Init{Directory: "foobar/"},
+		FMT:         &FMT{Directory: "foobar/"},
+		Plan:        &Plan{Directory: "foobar/"},
+		Validation:  &Validation{Directory: "foobar/"},
+		Workspace:   &Workspace{},
+	}
+
+	err := p.Validate()
+	if err != nil {
+		t.Errorf("Validate returned err: %v", err)
+	}
+}
+
+func TestTerraform_Plugin_Validate_InvalidAction(t *testing.T) {
+	// setup types
+	p := &Plugin{
+		Apply: &Apply{},
+		Config: &Config{
+			Actions: []string{"fmt", "foobar"},
+			Netrc:   &Netrc{},
+		},
+		Destroy:     &Destroy{},
+		Changes:     &Changes{},
+		Directories: &Directories{Concurrency: 1},
+		Environment: &Environment{},
+		Variables:   &Variables{},
+		Init:        &Init{},
+		FMT:         &FMT{},
+		Plan:        &Plan{},
+		Validation:  &Validation{},
+		Workspace:   &Workspace{},
+	}
+
+	err := p.Validate()
+	if !errors.Is(err, ErrInvalidAction) {
+		t.Errorf("Validate returned err %v, want %v", err, ErrInvalidAction)
+	}
+}
+
+func TestTerraform_Plugin_Validate_PlanStateConflict(t *testing.T) {
+	// setup types
+	p := &Plugin{
+		Apply: &Apply{Directory: "foobar/", StateOut: "terraform.tfstate"},
+		Config: &Config{
+			Actions: []string{"plan", "apply"},
+			Netrc:   &Netrc{},
+		},
+		Destroy:     &Destroy{},
+		Changes:     &Changes{},
+		Directories: &Directories{Concurrency: 1},
+		Environment: &Environment{},
+		Variables:   &Variables{},
+		Init:        &Init{Directory: "foobar/"},
+		FMT:         &FMT{},
+		Plan:        &Plan{Directory: "foobar/", Out: "terraform.tfstate"},
+		Validation:  &Validation{},
+		Workspace:   &Workspace{},
+	}
+
+	err := p.Validate()
+	if !errors.Is(err, ErrPlanStateConflict) {
+		t.Errorf("Validate returned err %v, want %v", err, ErrPlanStateConflict)
+	}
+}
+
+func TestTerraform_Plugin_Validate_Workspace(t *testing.T) {
+	// setup types
+	p := &Plugin{
+		Apply: &Apply{},
+		Config: &Config{
+			Actions: []string{"destroy"},
+			Netrc:   &Netrc{},
+		},
+		Destroy:     &Destroy{Directory: "foobar/", AutoApprove: true, Confirm: []string{"staging"}},
+		Changes:     &Changes{},
+		Directories: &Directories{Concurrency: 1},
+		Environment: &Environment{},
+		Variables:   &Variables{},
+		Init:        &Init{Directory: "foobar/"},
+		FMT:         &FMT{},
+		Plan:        &Plan{},
+		Validation:  &Validation{},
+		Workspace:   &Workspace{Name: "staging", Create: true},
+	}
+
+	err := p.Validate()
+	if err != nil {
+		t.Errorf("Validate returned err: %v", err)
+	}
+
+	p.Workspace.Name = ""
+
+	err = p.Validate()
+	if !errors.Is(err, ErrNoWorkspace) {
+		t.Errorf("Validate returned err %v, want %v", err, ErrNoWorkspace)
+	}
+}
+
+func TestTerraform_Plugin_Validate_DestroyPlan(t *testing.T) {
+	// setup types
+	p := &Plugin{
+		Apply:       &Apply{},
+		Config:      &Config{Actions: []string{"plan"}},
+		Destroy:     &Destroy{Directory: "foobar/"},
+		Changes:     &Changes{},
+		Directories: &Directories{Concurrency: 1},
+		Environment: &Environment{},
+		Variables:   &Variables{},
+		Init:        &Init{Directory: "foobar/", InitOptions: &InitOptions{}},
+		FMT:         &FMT{},
+		Plan:        &Plan{Destroy: true, Directory: "foobar/"},
+		Validation:  &Validation{},
+		Workspace:   &Workspace{},
+	}
+
+	// verify a destroy plan must be confirmed without auto approve
+	err := p.Validate()
+	if !errors.Is(err, ErrDestroyNotConfirmed) {
+		t.Errorf("Validate returned err %v, want %v", err, ErrDestroyNotConfirmed)
+	}
+
+	// verify a destroy plan is refused for a protected branch
+	t.Setenv("VELA_BUILD_BRANCH", "main")
+
+	p.Destroy.Confirm = []string{"foobar"}
+	p.Destroy.ProtectedPatterns = []string{"main"}
+
+	err = p.Validate()
+	if !errors.Is(err, ErrDestroyProtected) {
+		t.Errorf("Validate returned err %v, want %v", err, ErrDestroyProtected)
+	}
+}
+
+func TestTerraform_Plugin_forDirectory(t *testing.T) {
+	// setup types
+	p := &Plugin{
+		Apply:       &Apply{Directory: "."},
+		Config:      &Config{Actions: []string{"plan"}},
+		Destroy:     &Destroy{Directory: "."},
+		Changes:     &Changes{},
+		Directories: &Directories{Concurrency: 1},
+		Environment: &Environment{},
+		Variables:   &Variables{},
+		FMT:         &FMT{Directory: "."},
+		Init:        &Init{Archive: "init.tar.gz", Directory: "."},
+		Output:      &Output{Directory: "."},
+		Plan:        &Plan{Directory: "."},
+		Validation:  &Validation{Directory: "."},
+		Workspace:   &Workspace{Directory: "."},
+	}
+
+	got := p.forDirectory("stacks/app")
+
+	for _, dir := range []string{
+		got.Apply.Directory,
+		got.Destroy.Directory,
+		got.FMT.Directory,
+		got.Init.Directory,
+		got.Output.Directory,
+		got.Plan.Directory,
+		got.Validation.Directory,
+		got.Workspace.Directory,
+	} {
+		if dir != "stacks/app" {
+			t.Errorf("forDirectory directory is %v, want %v", dir, "stacks/app")
+		}
+	}
+
+	if got.Init.Archive != "stacks/app/init.tar.gz" {
+		t.Errorf("forDirectory archive is %v, want %v", got.Init.Archive, "stacks/app/init.tar.gz")
+	}
+
+	// verify apply uses the destroy safeguards for the directory
+	if got.Apply.Destroy != got.Destroy {
+		t.Errorf("forDirectory apply destroy is %v, want %v", got.Apply.Destroy, got.Destroy)
+	}
+
+	// verify the original plugin is unchanged
+	if p.Plan.Directory != "." {
+		t.Errorf("forDirectory modified plugin directory to %v", p.Plan.Directory)
+	}
+}
+
+func TestTerraform_Plugin_confirmDestroy(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name        string
+		actions     []string
+		confirm     []string
+		autoApprove bool
+		want        error
+	}{
+		{
+			name:        "single confirmation",
+			actions:     []string{"destroy"},
+			confirm:     []string{"staging"},
+			autoApprove: true,
+			want:        ErrDestroyNotConfirmed,
+		},
+		{
+			name:        "one directory confirmed",
+			actions:     []string{"destroy"},
+			confirm:     []string{"stacks/app/prod"},
+			autoApprove: true,
+			want:        ErrDestroyNotConfirmed,
+		},
+		{
+			name:        "each directory confirmed",
+			actions:     []string{"destroy"},
+			confirm:     []string{"stacks/app/prod", "stacks/db/prod"},
+			autoApprove: true,
+		},
+		{
+			name:    "destroy plan without auto approve",
+			actions: []string{"plan"},
+			confirm: []string{"stacks/app/prod"},
+			want:    ErrDestroyNotConfirmed,
+		},
+		{
+			name:    "destroy plan confirmed",
+			actions: []string{"plan"},
+			confirm: []string{"stacks/app/prod", "stacks/db/prod"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// setup types
+			p := &Plugin{
+				Apply:       &Apply{Directory: "."},
+				Config:      &Config{Actions: test.actions},
+				Destroy:     &Destroy{AutoApprove: test.autoApprove, Confirm: test.confirm, Directory: ".", Workspace: "staging"},
+				Changes:     &Changes{},
+				Directories: &Directories{Concurrency: 1, Patterns: []string{"stacks/*/prod"}},
+				Environment: &Environment{},
+				Variables:   &Variables{},
+				FMT:         &FMT{Directory: "."},
+				Init:        &Init{Directory: "."},
+				Output:      &Output{Directory: "."},
+				Plan:        &Plan{Destroy: true, Directory: "."},
+				Validation:  &Validation{Directory: "."},
+				Workspace:   &Workspace{Directory: ".", Name: "staging"},
+			}
+
+			err := p.confirmDestroy([]string{"stacks/app/prod", "stacks/db/prod"})
+			if !errors.Is(err, test.want) {
+				t.Errorf("confirmDestroy returned err %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestTerraform_Plugin_addVarFile(t *testing.T) {
+	// setup types
+	varFiles := make([]string, 1, 2)
+	varFiles[0] = "vars.tf"
+
+	p := &Plugin{
+		Apply:      &Apply{VarFiles: varFiles},
+		Destroy:    &Destroy{VarFiles: varFiles},
+		FMT:        &FMT{},
+		Init:       &Init{},
+		Output:     &Output{},
+		Plan:       &Plan{VarFiles: varFiles},
+		Validation: &Validation{VarFiles: varFiles},
+		Workspace:  &Workspace{},
+	}
+
+	// copy the plugin to verify the var files aren't shared
+	c := p.forDirectory("foobar")
+
+	c.addVarFile(_variablesFile)
+
+	want := []string{"vars.tf", _variablesFile}
+
+	for _, got := range [][]string{c.Apply.VarFiles, c.Destroy.VarFiles, c.Plan.VarFiles, c.Validation.VarFiles} {
+		if !slices.Equal(got, want) {
+			t.Errorf("addVarFile var files are %v, want %v", got, want)
+		}
+	}
+
+	p.addVarFile("other.tfvars.json")
+
+	if c.Plan.VarFiles[1] != _variablesFile {
+		t.Errorf("addVarFile modified copied var files to %v", c.Plan.VarFiles)
+	}
+}
@@ -0,0 +1,232 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// _addChange represents a resource that will be created.
+	_addChange = "add"
+	// _updateChange represents a resource that will be updated in-place.
+	_updateChange = "change"
+	// _destroyChange represents a resource that will be destroyed.
+	_destroyChange = "destroy"
+	// _replaceChange represents a resource that will be destroyed and created.
+	_replaceChange = "replace"
+)
+
+type (
+	// planJSON represents the machine readable output
+	// of a plan file from "terraform show -json".
+	planJSON struct {
+		// version of the JSON output format
+		FormatVersion string `json:"format_version"`
+		// version of terraform that created the plan
+		TerraformVersion string `json:"terraform_version"`
+		// changes planned for each resource instance
+		ResourceChanges []*resourceChange `json:"resource_changes,omitempty"`
+	}
+
+	// resourceChange represents the planned change
+	// for a single resource instance.
+	resourceChange struct {
+		// full address of the resource instance
+		Address string `json:"address"`
+		// address of the module containing the resource
+		ModuleAddress string `json:"module_address,omitempty"`
+		// mode of the resource i.e. "managed" or "data"
+		Mode string `json:"mode"`
+		// type of the resource i.e. "aws_s3_bucket"
+		Type string `json:"type"`
+		// name of the resource
+		Name string `json:"name"`
+		// change planned for the resource
+		Change *change `json:"change"`
+	}
+
+	// change represents the actions planned for a resource.
+	change struct {
+		// actions to perform i.e. ["delete", "create"]
+		Actions []string `json:"actions"`
+	}
+)
+
+// Kind returns the kind of change planned for the resource
+// or an empty string if the resource won't be modified.
+func (r *resourceChange) Kind() string {
+	// check if the change was provided
+	if r.Change == nil {
+		return ""
+	}
+
+	actions := r.Change.Actions
+
+	switch {
+	case slices.Contains(actions, "create") && slices.Contains(actions, "delete"):
+		return _replaceChange
+	case slices.Contains(actions, "create"):
+		return _addChange
+	case slices.Contains(actions, "update"):
+		return _updateChange
+	case slices.Contains(actions, "delete"):
+		return _destroyChange
+	default:
+		return ""
+	}
+}
+
+// Module returns the module address for the
+// resource or "root" for the root module.
+func (r *resourceChange) Module() string {
+	// check if the resource is in the root module
+	if len(r.ModuleAddress) == 0 {
+		return "root"
+	}
+
+	return r.ModuleAddress
+}
+
+// Changes returns the resources the plan will modify
+// grouped by the kind of change.
+func (p *planJSON) Changes() map[string][]*resourceChange {
+	changes := make(map[string][]*resourceChange)
+
+	for _, r := range p.ResourceChanges {
+		kind := r.Kind()
+
+		// skip resources that won't be modified
+		if len(kind) == 0 {
+			continue
+		}
+
+		changes[kind] = append(changes[kind], r)
+	}
+
+	return changes
+}
+
+// DestroyOnly returns true if the plan destroys
+// resources without adding, changing or replacing any.
+func (p *planJSON) DestroyOnly() bool {
+	changes := p.Changes()
+
+	return len(changes) == 1 && len(changes[_destroyChange]) > 0
+}
+
+// WriteSummary writes the counts of resources the plan
+// will modify, broken down by resource type and module.
+func (p *planJSON) WriteSummary(w io.Writer) {
+	changes := p.Changes()
+
+	fmt.Fprintf(w,
+		"\nPlan summary: %d to add, %d to change, %d to destroy, %d to replace\n",
+		len(changes[_addChange]),
+		len(changes[_updateChange]),
+		len(changes[_destroyChange]),
+		len(changes[_replaceChange]),
+	)
+
+	// check if the plan modifies any resources
+	if len(changes) == 0 {
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "\nchange\ttype\tmodule\tcount")
+
+	for _, kind := range []string{_addChange, _updateChange, _destroyChange, _replaceChange} {
+		// count the changes for each type and module
+		counts := make(map[[2]string]int)
+
+		for _, r := range changes[kind] {
+			counts[[2]string{r.Type, r.Module()}]++
+		}
+
+		keys := slices.SortedFunc(maps.Keys(counts), func(a, b [2]string) int {
+			return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+		})
+
+		for _, key := range keys {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", kind, key[0], key[1], counts[key])
+		}
+	}
+
+	tw.Flush()
+
+	// list the resources that will be removed since they deserve attention
+	for _, kind := range []string{_destroyChange, _replaceChange} {
+		for _, r := range changes[kind] {
+			fmt.Fprintf(w, "  %s: %s\n", kind, r.Address)
+		}
+	}
+}
+
+// showCmd is a helper function to output the
+// machine readable representation of a plan file.
+func showCmd(ctx context.Context, dir string, v *semver.Version, file string) *exec.Cmd {
+	logrus.Trace("creating terraform show command")
+
+	// variable to store flags for command
+	var args []string
+
+	// check if Directory is provided and terraform version supports chdir
+	if dir != "." && SupportsChdir(v) {
+		args = append(args, fmt.Sprintf("-chdir=%s", dir))
+	}
+
+	// check if Directory is provided and terraform version doesn't support chdir
+	if dir != "." && !SupportsChdir(v) {
+		file = localPath(dir, file)
+	}
+
+	args = append(args, "show", "-json", file)
+
+	//nolint:gosec // ignore G204
+	return exec.CommandContext(ctx, terraformBin, args...)
+}
+
+// readPlan is a helper function to parse the
+// provided plan file into its machine readable form.
+func readPlan(ctx context.Context, dir string, v *semver.Version, file string) (*planJSON, error) {
+	logrus.Tracef("reading plan file %s", file)
+
+	out, err := outputCmd(ctx, showCmd(ctx, dir, v, file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to show plan file %s: %w", file, err)
+	}
+
+	plan := new(planJSON)
+
+	err = json.Unmarshal(out, plan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse plan file %s: %w", file, err)
+	}
+
+	return plan, nil
+}
+
+// localPath is a helper function to resolve a path
+// relative to the directory terraform runs in.
+func localPath(dir, file string) string {
+	// check if the file is already an absolute path
+	if filepath.IsAbs(file) {
+		return file
+	}
+
+	return filepath.Join(dir, file)
+}
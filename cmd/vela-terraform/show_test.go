@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func TestTerraform_showCmd(t *testing.T) {
+	// setup types
+	tests := []struct {
+		version string
+		want    []string
+	}{
+		{
+			version: "1.0.0",
+			want:    []string{"-chdir=foobar/", "show", "-json", "plan.out"},
+		},
+		{
+			version: "0.13.0",
+			want:    []string{"show", "-json", "foobar/plan.out"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		v, _ := semver.NewVersion(test.version)
+
+		//nolint:gosec // ignore G204
+		want := exec.CommandContext(t.Context(), terraformBin, test.want...)
+
+		got := showCmd(t.Context(), "foobar/", v, "plan.out")
+		if got.Path != want.Path {
+			t.Errorf("showCmd path is %v, want %v", got.Path, want.Path)
+		}
+
+		if !slices.Equal(got.Args, want.Args) {
+			t.Errorf("showCmd args is %v, want %v", got.Args, want.Args)
+		}
+	}
+}
+
+func TestTerraform_localPath(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		dir  string
+		file string
+		want string
+	}{
+		{dir: ".", file: "plan.out", want: "plan.out"},
+		{dir: "foobar/", file: "plan.out", want: "foobar/plan.out"},
+		{dir: "foobar/", file: "/tmp/plan.out", want: "/tmp/plan.out"},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := localPath(test.dir, test.file)
+		if got != test.want {
+			t.Errorf("localPath is %v, want %v", got, test.want)
+		}
+	}
+}
+
+// testPlan represents a plan file as output by "terraform show -json".
+const testPlan = `{
+  "format_version": "1.2",
+  "terraform_version": "1.5.7",
+  "resource_changes": [
+    {"address": "aws_s3_bucket.logs", "mode": "managed", "type": "aws_s3_bucket", "name": "logs", "change": {"actions": ["create"]}},
+    {"address": "aws_s3_bucket.data", "mode": "managed", "type": "aws_s3_bucket", "name": "data", "change": {"actions": ["create"]}},
+    {"address": "module.db.aws_db_instance.main", "module_address": "module.db", "mode": "managed", "type": "aws_db_instance", "name": "main", "change": {"actions": ["delete", "create"]}},
+    {"address": "aws_iam_role.app", "mode": "managed", "type": "aws_iam_role", "name": "app", "change": {"actions": ["update"]}},
+    {"address": "aws_instance.old", "mode": "managed", "type": "aws_instance", "name": "old", "change": {"actions": ["delete"]}},
+    {"address": "aws_vpc.main", "mode": "managed", "type": "aws_vpc", "name": "main", "change": {"actions": ["no-op"]}},
+    {"address": "data.aws_region.current", "mode": "data", "type": "aws_region", "name": "current", "change": {"actions": ["read"]}}
+  ]
+}`
+
+func TestTerraform_planJSON_Changes(t *testing.T) {
+	// setup types
+	plan := new(planJSON)
+
+	err := json.Unmarshal([]byte(testPlan), plan)
+	if err != nil {
+		t.Errorf("Unable to unmarshal plan: %v", err)
+	}
+
+	want := map[string]int{
+		_addChange:     2,
+		_updateChange:  1,
+		_destroyChange: 1,
+		_replaceChange: 1,
+	}
+
+	got := plan.Changes()
+	if len(got) != len(want) {
+		t.Errorf("Changes is %v, want %v", got, want)
+	}
+
+	for kind, count := range want {
+		if len(got[kind]) != count {
+			t.Errorf("Changes for %s is %d, want %d", kind, len(got[kind]), count)
+		}
+	}
+}
+
+func TestTerraform_planJSON_DestroyOnly(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		actions [][]string
+		want    bool
+	}{
+		{actions: [][]string{{"delete"}, {"delete"}}, want: true},
+		{actions: [][]string{{"delete"}, {"create"}}, want: false},
+		{actions: [][]string{{"delete", "create"}}, want: false},
+		{actions: [][]string{{"no-op"}}, want: false},
+	}
+
+	// run tests
+	for _, test := range tests {
+		plan := new(planJSON)
+
+		for _, actions := range test.actions {
+			plan.ResourceChanges = append(plan.ResourceChanges, &resourceChange{Change: &change{Actions: actions}})
+		}
+
+		if got := plan.DestroyOnly(); got != test.want {
+			t.Errorf("DestroyOnly for %v is %v, want %v", test.actions, got, test.want)
+		}
+	}
+}
+
+func TestTerraform_planJSON_WriteSummary(t *testing.T) {
+	// setup types
+	plan := new(planJSON)
+
+	err := json.Unmarshal([]byte(testPlan), plan)
+	if err != nil {
+		t.Errorf("Unable to unmarshal plan: %v", err)
+	}
+
+	w := new(bytes.Buffer)
+
+	plan.WriteSummary(w)
+
+	for _, want := range []string{
+		"Plan summary: 2 to add, 1 to change, 1 to destroy, 1 to replace",
+		"add      aws_s3_bucket    root       2",
+		"replace  aws_db_instance  module.db  1",
+		"destroy: aws_instance.old",
+		"replace: module.db.aws_db_instance.main",
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("WriteSummary output is %q, want to contain %q", w.String(), want)
+		}
+	}
+}
+
+func TestTerraform_planJSON_WriteSummary_NoChanges(t *testing.T) {
+	// setup types
+	plan := &planJSON{TerraformVersion: "1.5.7"}
+
+	w := new(bytes.Buffer)
+
+	plan.WriteSummary(w)
+
+	want := "\nPlan summary: 0 to add, 0 to change, 0 to destroy, 0 to replace\n"
+	if w.String() != want {
+		t.Errorf("WriteSummary output is %q, want %q", w.String(), want)
+	}
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	// _sshKeyFile represents the name of the file
+	// generated with the SSH private key for git.
+	_sshKeyFile = "vela_terraform_key"
+	// _sshKnownHostsFile represents the name of the file
+	// generated with the SSH known hosts for git.
+	_sshKnownHostsFile = "vela_terraform_known_hosts"
+)
+
+// SSH is credentials for cloning over SSH.
+type SSH struct {
+	// private key used to authenticate with the remote hosts
+	Key string
+	// known hosts entries used to verify the remote hosts
+	KnownHosts string
+	// trust the keys of the remote hosts on the first connection without known hosts
+	TrustOnFirstUse bool
+}
+
+// writeSSH creates the SSH key and known hosts files in the
+// home directory and configures git to use them for terraform.
+func (c *Config) writeSSH(home string) error {
+	// check if an SSH key was provided
+	if c.SSH == nil || len(c.SSH.Key) == 0 {
+		return nil
+	}
+
+	logrus.Trace("writing SSH key and known hosts files")
+
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	key, knownHosts := sshPaths(home)
+
+	// send Filesystem call to create the .ssh directory only the owner can access
+	err := a.Fs.MkdirAll(filepath.Dir(key), 0700)
+	if err != nil {
+		return err
+	}
+
+	// ssh rejects private keys without a trailing newline or readable by others
+	err = a.WriteFile(key, []byte(strings.TrimRight(c.SSH.Key, "\r\n")+"\n"), 0600)
+	if err != nil {
+		return err
+	}
+
+	// hosts are verified against the known hosts file when provided
+	strict := "yes"
+
+	if len(c.SSH.KnownHosts) == 0 && c.SSH.TrustOnFirstUse {
+		logrus.Warn("no SSH known hosts provided, trusting the keys of hosts on first connection")
+
+		strict = "accept-new"
+	}
+
+	err = a.WriteFile(knownHosts, []byte(strings.TrimRight(c.SSH.KnownHosts, "\r\n")+"\n"), 0644)
+	if err != nil {
+		return err
+	}
+
+	// configure git to only use the provided key and known hosts
+	command := fmt.Sprintf(
+		"ssh -i %s -o IdentitiesOnly=yes -o UserKnownHostsFile=%s -o StrictHostKeyChecking=%s",
+		key, knownHosts, strict,
+	)
+
+	return os.Setenv("GIT_SSH_COMMAND", command)
+}
+
+// Remove deletes the SSH key and known hosts files
+// created in the home directory for the plugin.
+func (c *Config) Remove() error {
+	// check if an SSH key was provided
+	if c.SSH == nil || len(c.SSH.Key) == 0 {
+		return nil
+	}
+
+	logrus.Trace("removing SSH key and known hosts files")
+
+	key, knownHosts := sshPaths(homeDir())
+
+	for _, path := range []string{key, knownHosts} {
+		err := appFS.Remove(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return os.Unsetenv("GIT_SSH_COMMAND")
+}
+
+// sshPaths is a helper function to return the paths of the
+// SSH key and known hosts files in the home directory.
+func sshPaths(home string) (string, string) {
+	return filepath.Join(home, ".ssh", _sshKeyFile), filepath.Join(home, ".ssh", _sshKnownHostsFile)
+}
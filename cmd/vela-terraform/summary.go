@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// result represents the outcome of running a single unit of work.
+type result struct {
+	// name of the work that was run
+	Name string
+	// error returned from running the work
+	Err error
+	// reason the work was not run
+	Reason string
+	// if set, the work was not run
+	Skipped bool
+}
+
+// Status returns the human readable status for the result.
+func (r *result) Status() string {
+	switch {
+	case r.Skipped && len(r.Reason) > 0:
+		return fmt.Sprintf("skipped: %s", r.Reason)
+	case r.Skipped:
+		return "skipped"
+	case r.Err != nil:
+		return fmt.Sprintf("fail: %v", r.Err)
+	default:
+		return "pass"
+	}
+}
+
+// writeSummary is a helper function to write the
+// status for each of the provided results to w.
+func writeSummary(w io.Writer, kind string, results []*result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "\n%s\tstatus\n", kind)
+
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\n", r.Name, r.Status())
+	}
+
+	tw.Flush()
+}
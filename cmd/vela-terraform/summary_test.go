@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTerraform_result_Status(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		result *result
+		want   string
+	}{
+		{
+			result: &result{Name: "fmt"},
+			want:   "pass",
+		},
+		{
+			result: &result{Name: "validate", Err: errors.New("exit status 1")},
+			want:   "fail: exit status 1",
+		},
+		{
+			result: &result{Name: "plan", Skipped: true},
+			want:   "skipped",
+		},
+		{
+			result: &result{Name: "stacks/app", Reason: "dependency stacks/network failed", Skipped: true},
+			want:   "skipped: dependency stacks/network failed",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := test.result.Status()
+		if got != test.want {
+			t.Errorf("Status is %v, want %v", got, test.want)
+		}
+	}
+}
+
+func TestTerraform_writeSummary(t *testing.T) {
+	// setup types
+	results := []*result{
+		{Name: "fmt"},
+		{Name: "validate", Err: errors.New("exit status 1")},
+		{Name: "plan", Skipped: true},
+	}
+
+	w := new(bytes.Buffer)
+
+	writeSummary(w, "action", results)
+
+	for _, want := range []string{"action    status", "fmt       pass", "validate  fail: exit status 1", "plan      skipped"} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("writeSummary output is %q, want to contain %q", w.String(), want)
+		}
+	}
+}
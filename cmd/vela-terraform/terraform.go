@@ -4,8 +4,11 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -23,49 +26,290 @@ const (
 	_terraform  = _installDir + "/" + "terraform"
 )
 
-func installBinary(ctx context.Context, customVer, defaultVer string) error {
+// terraformBin represents the path to the engine
+// binary resolved for the requested version.
+var terraformBin = _terraform
+
+// _autoVersion represents the version used to detect
+// the engine version from the configuration.
+const _autoVersion = "auto"
+
+var (
+	// terraformBlock represents the regexp to match the start of a terraform block.
+	terraformBlock = regexp.MustCompile(`(?m)^\s*terraform\s*\{`)
+	// requiredVersion represents the regexp to match
+	// the required_version setting within a terraform block.
+	requiredVersion = regexp.MustCompile(`(?m)^\s*required_version\s*=\s*"([^"]+)"`)
+)
+
+// listVersions is a helper function to list the terraform
+// releases matching the constraints in ascending order.
+var listVersions = func(ctx context.Context, constraints version.Constraints) ([]*version.Version, error) {
+	v := &releases.Versions{
+		Product:     product.Terraform,
+		Constraints: constraints,
+	}
+
+	sources, err := v.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]*version.Version, 0, len(sources))
+
+	for _, s := range sources {
+		// only exact versions are returned from the list
+		if ev, ok := s.(*releases.ExactVersion); ok {
+			versions = append(versions, ev.Version)
+		}
+	}
+
+	return versions, nil
+}
+
+// installBinary installs the terraform version matching the custom version
+// to the cache if it differs from the default and returns the version.
+func installBinary(ctx context.Context, customVer, defaultVer, dir, cacheDir string, mirror *Mirror, tofu *Tofu) (string, error) {
 	// use custom filesystem which enables us to test
 	a := &afero.Afero{
 		Fs: appFS,
 	}
 
-	// check if the custom version matches the default version
-	if strings.EqualFold(customVer, defaultVer) {
+	// check if a version was provided
+	if len(customVer) == 0 {
+		// use the version installed in the image for the engine
+		customVer = defaultVer
+
+		// check if the image includes a version for the engine
+		if len(customVer) == 0 {
+			logrus.Infof("no %s version provided, using the latest release", engine.Name)
+
+			customVer = ">= 0"
+		}
+	}
+
+	// check if the version should be detected from the configuration
+	if strings.EqualFold(customVer, _autoVersion) {
+		detected, err := detectVersion(dir)
+		if err != nil {
+			return "", err
+		}
+
+		// check if a version was detected
+		if len(detected) == 0 && len(defaultVer) == 0 {
+			return "", fmt.Errorf("no %s version detected in %s", engine.Name, dir)
+		}
+
+		// check if a version was detected
+		if len(detected) == 0 {
+			logrus.Warnf("no terraform version detected in %s, using default: %s", dir, defaultVer)
+
+			return defaultVer, nil
+		}
+
+		logrus.Infof("detected terraform version %s in %s", detected, dir)
+
+		customVer = detected
+	}
+
+	// resolve the custom version to an exact version
+	resolved, err := resolveVersion(ctx, customVer, defaultVer, mirror)
+	if err != nil {
+		return "", err
+	}
+
+	// check if the resolved version matches the default version
+	if strings.EqualFold(resolved, defaultVer) {
 		// the terraform versions match so no action is required
-		return nil
+		return resolved, nil
 	}
 
-	// parse the custom version
-	v, err := version.NewVersion(customVer)
+	// parse the resolved version
+	v, err := version.NewVersion(resolved)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	logrus.Infof("custom terraform version requested: %s", customVer)
+	logrus.Infof("custom terraform version requested: %s", resolved)
 
 	logrus.Debugf("custom version does not match default: %s", defaultVer)
 
-	// rename the old terraform binary since we can't overwrite it for now
-	err = a.Rename(_terraform, fmt.Sprintf("%s.default", _terraform))
+	// each version is installed to its own directory in the cache
+	versionDir := filepath.Join(cacheDir, v.String())
+	bin := filepath.Join(versionDir, engine.Name)
+
+	// check if the version was installed to the cache previously
+	ok, err := a.Exists(bin)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	if ok {
+		logrus.Infof("using cached terraform %s from %s", resolved, bin)
+
+		terraformBin = bin
+
+		return resolved, nil
+	}
+
+	// send Filesystem call to create directory path for the version
+	err = a.MkdirAll(versionDir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case mirror != nil:
+		// install the custom version from the mirror
+		err = mirror.Install(v, bin)
+	case engine.Name == _tofuEngine:
+		// install the custom version from the OpenTofu releases
+		err = tofu.Install(ctx, v, bin)
+	default:
+		// use hc-install to install the custom version
+		err = installRelease(ctx, v, versionDir, bin)
 	}
 
-	// use hc-install to install the custom version
-	installer := install.NewInstaller()
-	_, err = installer.Install(ctx, []src.Installable{
+	if err != nil {
+		return "", err
+	}
+
+	terraformBin = bin
+
+	return resolved, nil
+}
+
+// installVersion is a helper function to install the terraform
+// release with hc-install to the directory and return the binary.
+var installVersion = func(ctx context.Context, v *version.Version, dir string) (string, error) {
+	return install.NewInstaller().Install(ctx, []src.Installable{
 		&releases.ExactVersion{
 			Product:    product.Terraform,
 			Version:    v,
-			InstallDir: _installDir,
+			InstallDir: dir,
 		},
 	})
+}
 
+// installRelease is a helper function to install the terraform release
+// to a temporary directory and move the binary to bin once complete.
+func installRelease(ctx context.Context, v *version.Version, versionDir, bin string) error {
+	tmp, err := afero.TempDir(appFS, versionDir, ".install-")
 	if err != nil {
 		return err
 	}
+	defer appFS.RemoveAll(tmp)
 
-	return nil
+	path, err := installVersion(ctx, v, tmp)
+	if err != nil {
+		return err
+	}
+
+	return appFS.Rename(path, bin)
+}
+
+// cacheDir is a helper function to return the directory
+// to cache installed terraform versions in by default.
+func cacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "vela-terraform")
+	}
+
+	return filepath.Join(dir, "vela-terraform")
+}
+
+// resolveVersion is a helper function to resolve the provided
+// version or constraints to the newest matching release.
+func resolveVersion(ctx context.Context, customVer, defaultVer string, mirror *Mirror) (string, error) {
+	// check if the custom version matches the default version
+	if strings.EqualFold(customVer, defaultVer) {
+		return customVer, nil
+	}
+
+	// check if the custom version is an exact version
+	_, err := version.NewVersion(customVer)
+	if err == nil {
+		return customVer, nil
+	}
+
+	// parse the custom version as constraints i.e. "~> 1.5"
+	constraints, err := version.NewConstraint(customVer)
+	if err != nil {
+		return "", fmt.Errorf("invalid terraform version %s: %w", customVer, err)
+	}
+
+	var versions []*version.Version
+
+	switch {
+	case mirror != nil:
+		// list the versions available from the mirror
+		versions, err = mirror.Versions(constraints)
+	case engine.Name == _tofuEngine:
+		// list the versions available from the OpenTofu releases
+		versions, err = listTofuVersions(ctx, constraints)
+	default:
+		versions, err = listVersions(ctx, constraints)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	// check if any releases match the constraints
+	if len(versions) == 0 {
+		return "", fmt.Errorf("no terraform release matches version %s", customVer)
+	}
+
+	// use the newest matching release
+	newest := versions[len(versions)-1]
+
+	logrus.Debugf("resolved terraform version %s to %s", customVer, newest)
+
+	return newest.String(), nil
+}
+
+// detectVersion is a helper function to read the version from
+// the engine version file or the required_version in dir.
+func detectVersion(dir string) (string, error) {
+	logrus.Tracef("detecting terraform version in %s", dir)
+
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	// check if a version file is provided in the directory
+	b, err := a.ReadFile(filepath.Join(dir, engine.VersionFile))
+	if err == nil {
+		v := strings.TrimSpace(string(b))
+
+		// check if the latest version is requested
+		if strings.EqualFold(v, "latest") {
+			return ">= 0", nil
+		}
+
+		return v, nil
+	}
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	src, err := readConfig(dir)
+	if err != nil {
+		return "", err
+	}
+
+	// only a terraform block sets the required version
+	for _, body := range blocks(stripComments(src), terraformBlock) {
+		match := requiredVersion.FindStringSubmatch(body)
+		if match != nil {
+			return match[1], nil
+		}
+	}
+
+	return "", nil
 }
 
 // sets up environment for terraform.
@@ -3,9 +3,13 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/hashicorp/go-version"
 	"github.com/spf13/afero"
 )
 
@@ -14,24 +18,161 @@ func TestTerraform_install(t *testing.T) {
 	appFS = afero.NewMemMapFs()
 
 	// run test
-	err := installBinary(t.Context(), "0.11.0", "0.11.0")
+	_, err := installBinary(t.Context(), "0.11.0", "0.11.0", ".", "/cache", nil, nil)
 	if err != nil {
 		t.Errorf("install returned err: %v", err)
 	}
 }
 
-func TestTerraform_install_NoBinary(t *testing.T) {
+func TestTerraform_install_Default(t *testing.T) {
 	// setup filesystem
 	appFS = afero.NewMemMapFs()
 
 	// run test
-	err := installBinary(t.Context(), "0.11.0", "0.12.0")
-	if err == nil {
-		t.Errorf("install should have returned err")
+	got, err := installBinary(t.Context(), "", "0.11.0", ".", "/cache", nil, nil)
+	if err != nil {
+		t.Errorf("install returned err: %v", err)
+	}
+
+	if got != "0.11.0" {
+		t.Errorf("install is %v, want %v", got, "0.11.0")
+	}
+}
+
+func TestTerraform_install_Cached(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	t.Cleanup(func() { terraformBin = _terraform })
+
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	// create cached binary file
+	err := a.WriteFile("/cache/0.11.0/terraform", []byte("!@#$%^&*()"), 0755)
+	if err != nil {
+		t.Errorf("Unable to write file: %v", err)
+	}
+
+	// run test twice to verify switching versions is idempotent
+	for range 2 {
+		got, err := installBinary(t.Context(), "0.11.0", "0.12.0", ".", "/cache", nil, nil)
+		if err != nil {
+			t.Errorf("install returned err: %v", err)
+		}
+
+		if got != "0.11.0" {
+			t.Errorf("install is %v, want %v", got, "0.11.0")
+		}
+
+		if terraformBin != "/cache/0.11.0/terraform" {
+			t.Errorf("terraformBin is %v, want %v", terraformBin, "/cache/0.11.0/terraform")
+		}
 	}
 }
 
 func TestTerraform_install_NotWritable(t *testing.T) {
+	// setup filesystem which rejects creating the cache directory
+	appFS = afero.NewReadOnlyFs(afero.NewMemMapFs())
+
+	original := installVersion
+	t.Cleanup(func() { installVersion = original })
+
+	installVersion = func(_ context.Context, _ *version.Version, _ string) (string, error) {
+		t.Errorf("installVersion should not have been called")
+
+		return "", nil
+	}
+
+	// run test
+	_, err := installBinary(t.Context(), "0.11.0", "0.12.0", ".", "/cache", nil, nil)
+	if !errors.Is(err, os.ErrPermission) {
+		t.Errorf("install returned err %v, want %v", err, os.ErrPermission)
+	}
+
+	if terraformBin != _terraform {
+		t.Errorf("terraformBin is %v, want %v", terraformBin, _terraform)
+	}
+}
+
+func TestTerraform_installRelease(t *testing.T) {
+	v := version.Must(version.NewVersion("1.5.7"))
+
+	// setup tests
+	tests := []struct {
+		name    string
+		content string
+		failure bool
+	}{
+		{
+			name:    "installed",
+			content: "#!/bin/sh",
+		},
+		{
+			name:    "failed",
+			content: "partial",
+			failure: true,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// setup filesystem
+			appFS = afero.NewMemMapFs()
+
+			original := installVersion
+			t.Cleanup(func() { installVersion = original })
+
+			installVersion = func(_ context.Context, _ *version.Version, dir string) (string, error) {
+				path := filepath.Join(dir, "terraform")
+
+				err := afero.WriteFile(appFS, path, []byte(test.content), 0755)
+				if err != nil {
+					return "", err
+				}
+
+				// fail after writing part of the binary
+				if test.failure {
+					return "", errors.New("download interrupted")
+				}
+
+				return path, nil
+			}
+
+			err := appFS.MkdirAll("/cache/1.5.7", 0755)
+			if err != nil {
+				t.Errorf("unable to create directory: %v", err)
+			}
+
+			err = installRelease(t.Context(), v, "/cache/1.5.7", "/cache/1.5.7/terraform")
+			if test.failure != (err != nil) {
+				t.Errorf("installRelease returned err: %v", err)
+			}
+
+			got, _ := afero.ReadFile(appFS, "/cache/1.5.7/terraform")
+
+			if test.failure && got != nil {
+				t.Errorf("installRelease should not have cached %q", got)
+			}
+
+			if !test.failure && string(got) != test.content {
+				t.Errorf("installRelease cached %q, want %q", got, test.content)
+			}
+
+			// verify the temporary directory is removed
+			entries, _ := afero.ReadDir(appFS, "/cache/1.5.7")
+			for _, e := range entries {
+				if e.IsDir() {
+					t.Errorf("installRelease should have removed %s", e.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestTerraform_install_Auto(t *testing.T) {
 	// setup filesystem
 	appFS = afero.NewMemMapFs()
 
@@ -39,16 +180,161 @@ func TestTerraform_install_NotWritable(t *testing.T) {
 		Fs: appFS,
 	}
 
-	// create binary file
-	err := a.WriteFile(_terraform, []byte("!@#$%^&*()"), 0777)
+	err := a.WriteFile("foobar/.terraform-version", []byte("1.5.7\n"), 0644)
 	if err != nil {
-		t.Errorf("Unable to write file %s: %v", _terraform, err)
+		t.Errorf("Unable to write file: %v", err)
+	}
+
+	// run test
+	got, err := installBinary(t.Context(), "auto", "1.5.7", "foobar", "/cache", nil, nil)
+	if err != nil {
+		t.Errorf("install returned err: %v", err)
+	}
+
+	if got != "1.5.7" {
+		t.Errorf("install is %v, want %v", got, "1.5.7")
 	}
+}
+
+func TestTerraform_install_AutoNotDetected(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
 
 	// run test
-	err = installBinary(t.Context(), "0.11.0", "0.12.0")
-	if err == nil {
-		t.Errorf("install should have returned err")
+	got, err := installBinary(t.Context(), "auto", "1.7.4", "foobar", "/cache", nil, nil)
+	if err != nil {
+		t.Errorf("install returned err: %v", err)
+	}
+
+	if got != "1.7.4" {
+		t.Errorf("install is %v, want %v", got, "1.7.4")
+	}
+}
+
+func TestTerraform_resolveVersion(t *testing.T) {
+	// setup types
+	original := listVersions
+	t.Cleanup(func() { listVersions = original })
+
+	listVersions = func(_ context.Context, c version.Constraints) ([]*version.Version, error) {
+		var versions []*version.Version
+
+		for _, v := range []string{"1.3.9", "1.5.0", "1.5.7", "1.6.6", "1.7.4"} {
+			ver := version.Must(version.NewVersion(v))
+
+			if c.Check(ver) {
+				versions = append(versions, ver)
+			}
+		}
+
+		return versions, nil
+	}
+
+	// setup tests
+	tests := []struct {
+		version string
+		want    string
+		failure bool
+	}{
+		{version: "1.7.4", want: "1.7.4"},
+		{version: "1.2.0", want: "1.2.0"},
+		{version: "~> 1.5.0", want: "1.5.7"},
+		{version: ">= 1.3, < 1.7", want: "1.6.6"},
+		{version: ">= 2.0", failure: true},
+		{version: "foobar", failure: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := resolveVersion(t.Context(), test.version, "1.7.4", nil)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("resolveVersion for %s should have returned err", test.version)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("resolveVersion for %s returned err: %v", test.version, err)
+		}
+
+		if got != test.want {
+			t.Errorf("resolveVersion for %s is %v, want %v", test.version, got, test.want)
+		}
+	}
+}
+
+func TestTerraform_detectVersion(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		files map[string]string
+		want  string
+	}{
+		{
+			files: map[string]string{"foobar/.terraform-version": "1.5.7\n"},
+			want:  "1.5.7",
+		},
+		{
+			files: map[string]string{"foobar/.terraform-version": "latest"},
+			want:  ">= 0",
+		},
+		{
+			files: map[string]string{
+				"foobar/main.tf":     "resource \"null_resource\" \"foo\" {}\n",
+				"foobar/versions.tf": "terraform {\n  required_version = \"~> 1.5\"\n}\n",
+			},
+			want: "~> 1.5",
+		},
+		{
+			files: map[string]string{
+				"foobar/main.tf": "locals {\n  required_version = \"1.0.0\"\n}\n",
+				"foobar/versions.tf": `terraform {
+  # required_version = "~> 1.3"
+  /*
+  required_version = "~> 1.4"
+  */
+  required_version = "~> 1.5" // pinned for the state
+}
+`,
+			},
+			want: "~> 1.5",
+		},
+		{
+			files: map[string]string{"foobar/main.tf": "# terraform {\n#   required_version = \"~> 1.5\"\n# }\n"},
+			want:  "",
+		},
+		{
+			files: map[string]string{"foobar/main.tf": "resource \"null_resource\" \"foo\" {}\n"},
+			want:  "",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		// setup filesystem
+		appFS = afero.NewMemMapFs()
+
+		a := &afero.Afero{
+			Fs: appFS,
+		}
+
+		for name, content := range test.files {
+			err := a.WriteFile(name, []byte(content), 0644)
+			if err != nil {
+				t.Errorf("Unable to write file %s: %v", name, err)
+			}
+		}
+
+		got, err := detectVersion("foobar")
+		if err != nil {
+			t.Errorf("detectVersion returned err: %v", err)
+		}
+
+		if got != test.want {
+			t.Errorf("detectVersion is %v, want %v", got, test.want)
+		}
 	}
 }
 
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+var (
+	// tofuAPI represents the URL listing the OpenTofu releases.
+	tofuAPI = "https://get.opentofu.org/tofu/api.json"
+	// tofuReleases represents the URL to download the OpenTofu releases from.
+	tofuReleases = "https://github.com/opentofu/opentofu/releases/download"
+)
+
+// Tofu represents the plugin configuration for verifying the OpenTofu releases.
+type Tofu struct {
+	// SHA256 checksum the release zip must match
+	Checksum string
+	// armored GPG public key used to verify the SHA256SUMS signature
+	Key string
+}
+
+// listTofuVersions is a helper function to list the OpenTofu
+// releases matching the constraints in ascending order.
+func listTofuVersions(ctx context.Context, constraints version.Constraints) ([]*version.Version, error) {
+	logrus.Tracef("listing tofu versions from %s", tofuAPI)
+
+	b, err := download(ctx, tofuAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	// releases are listed as {"versions": [{"id": "1.6.0"}]}
+	releases := struct {
+		Versions []struct {
+			ID string `json:"id"`
+		} `json:"versions"`
+	}{}
+
+	err = json.Unmarshal(b, &releases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tofu releases: %w", err)
+	}
+
+	var versions version.Collection
+
+	for _, r := range releases.Versions {
+		v, err := version.NewVersion(r.ID)
+		if err != nil {
+			logrus.Debugf("skipping invalid tofu version %s: %v", r.ID, err)
+
+			continue
+		}
+
+		// skip any versions that don't match the constraints
+		if !constraints.Check(v) {
+			continue
+		}
+
+		versions = append(versions, v)
+	}
+
+	sort.Sort(versions)
+
+	return versions, nil
+}
+
+// Install downloads and verifies the OpenTofu
+// release for the version and installs it to path.
+func (t *Tofu) Install(ctx context.Context, v *version.Version, path string) error {
+	logrus.Infof("installing tofu %s from %s", v, tofuReleases)
+
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	// releases are stored with a tag per version i.e. "v1.6.0/"
+	releaseURL := fmt.Sprintf("%s/v%s", tofuReleases, v)
+	sumsFile := fmt.Sprintf("%s_%s_SHA256SUMS", _tofuEngine, v)
+	zipFile := fmt.Sprintf("%s_%s_%s_%s.zip", _tofuEngine, v, runtime.GOOS, runtime.GOARCH)
+
+	sums, err := download(ctx, fmt.Sprintf("%s/%s", releaseURL, sumsFile))
+	if err != nil {
+		return err
+	}
+
+	// check if a key was provided to verify the checksums
+	if len(t.Key) > 0 {
+		sig, err := download(ctx, fmt.Sprintf("%s/%s.gpgsig", releaseURL, sumsFile))
+		if err != nil {
+			return err
+		}
+
+		err = verifySignature(t.Key, sums, sig)
+		if err != nil {
+			return err
+		}
+	} else if len(t.Checksum) == 0 {
+		logrus.Warn("no tofu_key or tofu_checksum provided, only verifying the checksums downloaded with the release")
+	}
+
+	release, err := download(ctx, fmt.Sprintf("%s/%s", releaseURL, zipFile))
+	if err != nil {
+		return err
+	}
+
+	err = verifyChecksum(sums, zipFile, release)
+	if err != nil {
+		return err
+	}
+
+	// check if the checksum of the release was pinned
+	if len(t.Checksum) > 0 {
+		sum := sha256.Sum256(release)
+
+		if !strings.EqualFold(t.Checksum, hex.EncodeToString(sum[:])) {
+			return fmt.Errorf("%w: %s does not match tofu_checksum", ErrChecksumMismatch, zipFile)
+		}
+	}
+
+	return extractBinary(a, release, _tofuEngine, path)
+}
+
+// download is a helper function to retrieve
+// the contents of the provided URL.
+func download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// check if the download was successful
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to download %s: %s", url, resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
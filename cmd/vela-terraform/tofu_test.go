@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/hashicorp/go-version"
+	"github.com/spf13/afero"
+)
+
+// setupTofu is a helper function to serve the OpenTofu releases API
+// and a release for 1.6.2 signed by the provided signer and return it.
+func setupTofu(t *testing.T, signer *openpgp.Entity) []byte {
+	t.Helper()
+
+	name := fmt.Sprintf("tofu_1.6.2_%s_%s.zip", runtime.GOOS, runtime.GOARCH)
+	release := releaseZip(t, "tofu", "tofu 1.6.2")
+	sums := fmt.Appendf(nil, "%x  %s\n", sha256.Sum256(release), name)
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/tofu/api.json", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"versions": [{"id": "1.7.0-beta1"}, {"id": "1.6.2"}, {"id": "1.6.0"}, {"id": "1.5.0"}]}`)
+	})
+
+	mux.HandleFunc("/releases/v1.6.2/tofu_1.6.2_SHA256SUMS", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(sums)
+	})
+
+	// sign the checksums with the provided signer
+	if signer != nil {
+		sig := new(bytes.Buffer)
+
+		err := openpgp.DetachSign(sig, signer, bytes.NewReader(sums), nil)
+		if err != nil {
+			t.Fatalf("unable to sign checksums: %v", err)
+		}
+
+		mux.HandleFunc("/releases/v1.6.2/tofu_1.6.2_SHA256SUMS.gpgsig", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write(sig.Bytes())
+		})
+	}
+
+	mux.HandleFunc("/releases/v1.6.2/"+name, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(release)
+	})
+
+	s := httptest.NewServer(mux)
+
+	api, releases := tofuAPI, tofuReleases
+
+	tofuAPI = s.URL + "/tofu/api.json"
+	tofuReleases = s.URL + "/releases"
+
+	t.Cleanup(func() {
+		s.Close()
+
+		tofuAPI, tofuReleases = api, releases
+	})
+
+	return release
+}
+
+func TestTerraform_listTofuVersions(t *testing.T) {
+	setupTofu(t, nil)
+
+	constraints, _ := version.NewConstraint("~> 1.6.0")
+
+	got, err := listTofuVersions(t.Context(), constraints)
+	if err != nil {
+		t.Errorf("listTofuVersions returned err: %v", err)
+	}
+
+	if len(got) != 2 || got[0].String() != "1.6.0" || got[1].String() != "1.6.2" {
+		t.Errorf("listTofuVersions is %v, want [1.6.0 1.6.2]", got)
+	}
+}
+
+func TestTerraform_Tofu_Install(t *testing.T) {
+	setupTofu(t, nil)
+
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	err := new(Tofu).Install(t.Context(), version.Must(version.NewVersion("1.6.2")), "/cache/1.6.2/tofu")
+	if err != nil {
+		t.Errorf("Install returned err: %v", err)
+	}
+
+	got, _ := afero.ReadFile(appFS, "/cache/1.6.2/tofu")
+	if string(got) != "tofu 1.6.2" {
+		t.Errorf("Install wrote %q, want %q", got, "tofu 1.6.2")
+	}
+}
+
+func TestTerraform_Tofu_Install_NotFound(t *testing.T) {
+	setupTofu(t, nil)
+
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	err := new(Tofu).Install(t.Context(), version.Must(version.NewVersion("1.6.0")), "/cache/1.6.0/tofu")
+	if err == nil {
+		t.Errorf("Install should have returned err")
+	}
+}
+
+func TestTerraform_Tofu_Install_Signature(t *testing.T) {
+	signer, key := armoredKey(t)
+	_, other := armoredKey(t)
+
+	// setup tests
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "valid signature", key: key},
+		{name: "invalid signature", key: other, wantErr: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setupTofu(t, signer)
+
+			// setup filesystem
+			appFS = afero.NewMemMapFs()
+
+			err := (&Tofu{Key: test.key}).Install(t.Context(), version.Must(version.NewVersion("1.6.2")), "/cache/1.6.2/tofu")
+			if (err != nil) != test.wantErr {
+				t.Errorf("Install returned err %v, want err %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestTerraform_Tofu_Install_Checksum(t *testing.T) {
+	release := setupTofu(t, nil)
+
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	sum := sha256.Sum256(release)
+
+	err := (&Tofu{Checksum: fmt.Sprintf("%x", sum)}).Install(t.Context(), version.Must(version.NewVersion("1.6.2")), "/cache/1.6.2/tofu")
+	if err != nil {
+		t.Errorf("Install returned err: %v", err)
+	}
+
+	err = (&Tofu{Checksum: fmt.Sprintf("%x", sha256.Sum256(nil))}).Install(t.Context(), version.Must(version.NewVersion("1.6.2")), "/cache/1.6.2/tofu")
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Install returned err %v, want %v", err, ErrChecksumMismatch)
+	}
+}
+
+func TestTerraform_install_Tofu(t *testing.T) {
+	setupTofu(t, nil)
+
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	t.Cleanup(func() {
+		engine = engines[_terraformEngine]
+		terraformBin = _terraform
+	})
+
+	engine = engines[_tofuEngine]
+
+	got, err := installBinary(t.Context(), ">= 1.6", "", ".", "/cache", nil, new(Tofu))
+	if err != nil {
+		t.Errorf("install returned err: %v", err)
+	}
+
+	if got != "1.6.2" {
+		t.Errorf("install is %v, want %v", got, "1.6.2")
+	}
+
+	if terraformBin != "/cache/1.6.2/tofu" {
+		t.Errorf("terraformBin is %v, want %v", terraformBin, "/cache/1.6.2/tofu")
+	}
+}
+
+func TestTerraform_install_TofuLatest(t *testing.T) {
+	setupTofu(t, nil)
+
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	t.Cleanup(func() {
+		engine = engines[_terraformEngine]
+		terraformBin = _terraform
+	})
+
+	engine = engines[_tofuEngine]
+
+	got, err := installBinary(t.Context(), "", "", ".", "/cache", nil, new(Tofu))
+	if err != nil {
+		t.Errorf("install returned err: %v", err)
+	}
+
+	if got != "1.6.2" {
+		t.Errorf("install is %v, want %v", got, "1.6.2")
+	}
+}
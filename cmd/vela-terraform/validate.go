@@ -45,8 +45,8 @@ func (v *Validation) Command(ctx context.Context) *exec.Cmd {
 		globalFlags = append(flags, fmt.Sprintf("-chdir=%s", v.Directory))
 	}
 
-	// check if CheckVariables is provided
-	if v.CheckVariables {
+	// check if CheckVariables is provided and supported by the version
+	if v.CheckVariables && supported(_checkVariables, v.Version) {
 		// add flag for CheckVariables from provided validate command
 		flags = append(flags, fmt.Sprintf("-check-variables=%t", v.CheckVariables))
 	}
@@ -57,16 +57,13 @@ func (v *Validation) Command(ctx context.Context) *exec.Cmd {
 		flags = append(flags, "-no-color")
 	}
 
-	// check if Vars is provided
-	if len(v.Vars) > 0 {
+	// check if Vars or VarFiles is provided and supported by the version
+	if (len(v.Vars) > 0 || len(v.VarFiles) > 0) && supported(_validateVars, v.Version) {
 		for _, v := range v.Vars {
 			// add flag for Vars from provided command
 			flags = append(flags, fmt.Sprintf(`-var=%s`, v))
 		}
-	}
 
-	// check if VarFiles is provided
-	if len(v.VarFiles) > 0 {
 		for _, v := range v.VarFiles {
 			// add flag for VarFiles from provided command
 			flags = append(flags, fmt.Sprintf(`-var-file=%s`, v))
@@ -81,7 +78,7 @@ func (v *Validation) Command(ctx context.Context) *exec.Cmd {
 	globalFlags = append(globalFlags, validationAction)
 
 	//nolint:gosec // ignore G204
-	return exec.CommandContext(ctx, _terraform, append(globalFlags, flags...)...)
+	return exec.CommandContext(ctx, terraformBin, append(globalFlags, flags...)...)
 }
 
 // Exec formats and runs the commands for validating Terraform.
@@ -92,7 +89,7 @@ func (v *Validation) Exec(ctx context.Context) error {
 	cmd := v.Command(ctx)
 
 	// run the validate command for the file
-	err := execCmd(cmd)
+	err := execCmd(ctx, cmd)
 	if err != nil {
 		return err
 	}
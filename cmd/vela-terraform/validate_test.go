@@ -26,15 +26,10 @@ func TestTerraform_Validation_Command(t *testing.T) {
 	//nolint:gosec // ignore G204
 	want := exec.CommandContext(
 		t.Context(),
-		_terraform,
+		terraformBin,
 		fmt.Sprintf("-chdir=%s", v.Directory),
 		validationAction,
-		fmt.Sprintf("-check-variables=%t", v.CheckVariables),
 		"-no-color",
-		fmt.Sprintf("-var=%s", v.Vars[0]),
-		fmt.Sprintf("-var=%s", v.Vars[1]),
-		fmt.Sprintf("-var-file=%s", v.VarFiles[0]),
-		fmt.Sprintf("-var-file=%s", v.VarFiles[1]),
 	)
 
 	got := v.Command(t.Context())
@@ -62,7 +57,38 @@ func TestTerraform_Validation_Command_tf13(t *testing.T) {
 	//nolint:gosec // ignore G204
 	want := exec.CommandContext(
 		t.Context(),
-		_terraform,
+		terraformBin,
+		validationAction,
+		"-no-color",
+		fmt.Sprint(v.Directory),
+	)
+
+	got := v.Command(t.Context())
+	if got.Path != want.Path {
+		t.Errorf("Command path is %v, want %v", got.Path, want.Path)
+	}
+
+	if !slices.Equal(got.Args, want.Args) {
+		t.Errorf("Command args is %v, want %v", got.Args, want.Args)
+	}
+}
+
+func TestTerraform_Validation_Command_tf11(t *testing.T) {
+	ver, _ := semver.NewVersion("0.11.0")
+	// setup types
+	v := &Validation{
+		CheckVariables: true,
+		Directory:      "foobar/",
+		NoColor:        true,
+		Vars:           []string{"foo=bar", "bar=foo"},
+		VarFiles:       []string{"vars1.tf", "vars2.tf"},
+		Version:        ver,
+	}
+
+	//nolint:gosec // ignore G204
+	want := exec.CommandContext(
+		t.Context(),
+		terraformBin,
 		validationAction,
 		fmt.Sprintf("-check-variables=%t", v.CheckVariables),
 		"-no-color",
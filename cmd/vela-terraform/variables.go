@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// _variablesFile represents the name of the file
+// generated with the variables provided to the plugin.
+const _variablesFile = "vela.auto.tfvars.json"
+
+// Variables represents the plugin configuration for structured variables.
+type Variables struct {
+	// raw input of the YAML or JSON map of variables provided for plugin
+	Raw string
+	// variables parsed from the raw input
+	values map[string]json.RawMessage
+}
+
+// Unmarshal parses the raw input of the variables.
+func (v *Variables) Unmarshal() error {
+	logrus.Trace("unmarshalling variables")
+
+	v.values = nil
+
+	// check if any variables were passed
+	if len(v.Raw) == 0 {
+		return nil
+	}
+
+	// check if the variables are JSON to keep the values exactly as provided
+	if json.Valid([]byte(v.Raw)) {
+		err := json.Unmarshal([]byte(v.Raw), &v.values)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal variables: %w", err)
+		}
+
+		return nil
+	}
+
+	values := make(map[string]any)
+
+	err := yaml.Unmarshal([]byte(v.Raw), &values)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal variables: %w", err)
+	}
+
+	v.values = make(map[string]json.RawMessage, len(values))
+
+	for name, value := range values {
+		b, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal variable %s: %w", name, err)
+		}
+
+		v.values[name] = b
+	}
+
+	return nil
+}
+
+// Write creates the var file with the variables in the directory
+// and returns the path to the var file for the terraform version.
+func (v *Variables) Write(dir string, version *semver.Version) (string, error) {
+	// check if any variables were passed
+	if len(v.values) == 0 {
+		return "", nil
+	}
+
+	logrus.Tracef("writing variables to %s", filepath.Join(dir, _variablesFile))
+
+	// use custom filesystem which enables us to test
+	a := &afero.Afero{
+		Fs: appFS,
+	}
+
+	b, err := json.MarshalIndent(v.values, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	// variables may contain secrets so only the owner can read them
+	err = a.WriteFile(filepath.Join(dir, _variablesFile), append(b, '\n'), 0600)
+	if err != nil {
+		return "", err
+	}
+
+	// check if Directory is provided and terraform version doesn't support chdir
+	if dir != "." && !SupportsChdir(version) {
+		return localPath(dir, _variablesFile), nil
+	}
+
+	return _variablesFile, nil
+}
+
+// Remove deletes the var file with the variables from the directory.
+func (v *Variables) Remove(dir string) error {
+	// check if any variables were passed
+	if len(v.values) == 0 {
+		return nil
+	}
+
+	logrus.Tracef("removing variables from %s", filepath.Join(dir, _variablesFile))
+
+	err := appFS.Remove(filepath.Join(dir, _variablesFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
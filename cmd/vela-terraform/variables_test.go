@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/spf13/afero"
+)
+
+func TestTerraform_Variables_Write(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name    string
+		raw     string
+		dir     string
+		version string
+		path    string
+		want    string
+	}{
+		{
+			name:    "json",
+			raw:     `{"tags": {"team": "a,b"}, "zones": ["us-east-1a", "us-east-1b"], "count": 10000000000000001}`,
+			dir:     "foobar",
+			version: "1.0.0",
+			path:    _variablesFile,
+			want: `{
+  "count": 10000000000000001,
+  "tags": {
+    "team": "a,b"
+  },
+  "zones": [
+    "us-east-1a",
+    "us-east-1b"
+  ]
+}
+`,
+		},
+		{
+			name:    "yaml",
+			raw:     "enabled: true\nzones:\n  - us-east-1a\n  - us-east-1b\n",
+			dir:     "foobar",
+			version: "0.13.0",
+			path:    "foobar/" + _variablesFile,
+			want: `{
+  "enabled": true,
+  "zones": [
+    "us-east-1a",
+    "us-east-1b"
+  ]
+}
+`,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// setup filesystem
+			appFS = afero.NewMemMapFs()
+
+			v := &Variables{Raw: test.raw}
+
+			err := v.Unmarshal()
+			if err != nil {
+				t.Errorf("Unmarshal returned err: %v", err)
+			}
+
+			path, err := v.Write(test.dir, semver.MustParse(test.version))
+			if err != nil {
+				t.Errorf("Write returned err: %v", err)
+			}
+
+			if path != test.path {
+				t.Errorf("Write path is %v, want %v", path, test.path)
+			}
+
+			got, err := afero.ReadFile(appFS, "foobar/"+_variablesFile)
+			if err != nil {
+				t.Errorf("unable to read var file: %v", err)
+			}
+
+			if string(got) != test.want {
+				t.Errorf("Write is %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestTerraform_Variables_Remove(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	v := &Variables{Raw: `{"db_password": "hunter2"}`}
+
+	err := v.Unmarshal()
+	if err != nil {
+		t.Errorf("Unmarshal returned err: %v", err)
+	}
+
+	_, err = v.Write("foobar", semver.MustParse("1.0.0"))
+	if err != nil {
+		t.Errorf("Write returned err: %v", err)
+	}
+
+	err = v.Remove("foobar")
+	if err != nil {
+		t.Errorf("Remove returned err: %v", err)
+	}
+
+	ok, _ := afero.Exists(appFS, "foobar/"+_variablesFile)
+	if ok {
+		t.Errorf("Remove should have deleted %s", _variablesFile)
+	}
+
+	// verify removing again doesn't fail for the missing file
+	err = v.Remove("foobar")
+	if err != nil {
+		t.Errorf("Remove returned err: %v", err)
+	}
+}
+
+func TestTerraform_Variables_Write_Empty(t *testing.T) {
+	// setup filesystem
+	appFS = afero.NewMemMapFs()
+
+	v := &Variables{}
+
+	err := v.Unmarshal()
+	if err != nil {
+		t.Errorf("Unmarshal returned err: %v", err)
+	}
+
+	path, err := v.Write(".", nil)
+	if err != nil {
+		t.Errorf("Write returned err: %v", err)
+	}
+
+	if len(path) > 0 {
+		t.Errorf("Write path is %v, want empty", path)
+	}
+}
+
+func TestTerraform_Variables_Unmarshal_Invalid(t *testing.T) {
+	v := &Variables{Raw: "- foo\n- bar"}
+
+	err := v.Unmarshal()
+	if err == nil {
+		t.Errorf("Unmarshal should have returned err")
+	}
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/sirupsen/logrus"
+)
+
+const workspaceAction = "workspace"
+
+const (
+	// _workspaceList represents the workspace command to list workspaces.
+	_workspaceList = "list"
+	// _workspaceNew represents the workspace command to create a workspace.
+	_workspaceNew = "new"
+	// _workspaceDelete represents the workspace command to delete a workspace.
+	_workspaceDelete = "delete"
+	// _workspaceSelect represents the workspace command to select a workspace.
+	_workspaceSelect = "select"
+)
+
+var (
+	// ErrInvalidWorkspaceCommand defines the error type when the
+	// command provided to the workspace action is unsupported.
+	ErrInvalidWorkspaceCommand = errors.New("invalid workspace command provided")
+
+	// ErrNoWorkspace defines the error type when a
+	// workspace name is required but not provided.
+	ErrNoWorkspace = errors.New("no workspace provided")
+)
+
+// Workspace represents the plugin configuration for workspace information.
+type Workspace struct {
+	// workspace command to run for the workspace action i.e. "list", "new" or "delete"
+	Command string
+	// if set, create the workspace when it doesn't exist
+	Create bool
+	// terraform directory to manage workspaces for
+	Directory string
+	// name of the workspace to select, create or delete
+	Name    string
+	Version *semver.Version
+}
+
+// Subcommand formats and outputs the workspace subcommand
+// from the provided configuration for the workspace.
+func (w *Workspace) Subcommand(ctx context.Context, subcommand string) *exec.Cmd {
+	logrus.Tracef("creating terraform workspace %s command from plugin configuration", subcommand)
+
+	// global Variables
+	var globalFlags []string
+
+	// variable to store flags for command
+	var flags []string
+
+	// check if Directory is provided and terraform version supports chdir
+	if w.Directory != "." && SupportsChdir(w.Version) {
+		globalFlags = append(globalFlags, fmt.Sprintf("-chdir=%s", w.Directory))
+	}
+
+	// check if the subcommand operates on a named workspace
+	if subcommand != _workspaceList {
+		flags = append(flags, w.Name)
+	}
+
+	// check if Directory is provided and terraform version doesn't support chdir
+	if w.Directory != "." && !SupportsChdir(w.Version) {
+		flags = append(flags, w.Directory)
+	}
+
+	globalFlags = append(globalFlags, workspaceAction, subcommand)
+
+	//nolint:gosec // ignore G204
+	return exec.CommandContext(ctx, terraformBin, append(globalFlags, flags...)...)
+}
+
+// Exec formats and runs the commands for managing Terraform workspaces.
+func (w *Workspace) Exec(ctx context.Context) error {
+	logrus.Trace("running workspace with provided configuration")
+
+	// run the workspace command
+	return execCmd(ctx, w.Subcommand(ctx, w.Command))
+}
+
+// Select selects the configured workspace for the working
+// directory and creates it when it doesn't exist if enabled.
+func (w *Workspace) Select(ctx context.Context) error {
+	// check if a workspace is provided
+	if len(w.Name) == 0 {
+		return nil
+	}
+
+	logrus.Tracef("selecting workspace %s", w.Name)
+
+	// select the workspace
+	err := execCmd(ctx, w.Subcommand(ctx, _workspaceSelect))
+	if err == nil || !w.Create {
+		return err
+	}
+
+	logrus.Infof("workspace %s could not be selected, creating it", w.Name)
+
+	// create the workspace which also selects it
+	return execCmd(ctx, w.Subcommand(ctx, _workspaceNew))
+}
+
+// Validate verifies the Workspace is properly configured.
+func (w *Workspace) Validate() error {
+	logrus.Trace("validating workspace plugin configuration")
+
+	switch w.Command {
+	case _workspaceList:
+		return nil
+	case _workspaceNew, _workspaceDelete:
+		// verify Name is provided
+		if len(w.Name) == 0 {
+			return fmt.Errorf("%w: workspace is required for %s", ErrNoWorkspace, w.Command)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf(
+			"%w: %s (Valid commands: %s, %s, %s)",
+			ErrInvalidWorkspaceCommand,
+			w.Command,
+			_workspaceList,
+			_workspaceNew,
+			_workspaceDelete,
+		)
+	}
+}
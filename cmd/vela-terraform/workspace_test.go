@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"slices"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+func TestTerraform_Workspace_Subcommand(t *testing.T) {
+	v, _ := semver.NewVersion("1.0.0")
+	// setup types
+	w := &Workspace{
+		Directory: "foobar/",
+		Name:      "staging",
+		Version:   v,
+	}
+
+	// setup tests
+	tests := []struct {
+		subcommand string
+		want       *exec.Cmd
+	}{
+		{
+			subcommand: "list",
+			//nolint:gosec // ignore G204
+			want: exec.CommandContext(t.Context(), terraformBin, "-chdir=foobar/", "workspace", "list"),
+		},
+		{
+			subcommand: "select",
+			//nolint:gosec // ignore G204
+			want: exec.CommandContext(t.Context(), terraformBin, "-chdir=foobar/", "workspace", "select", "staging"),
+		},
+		{
+			subcommand: "new",
+			//nolint:gosec // ignore G204
+			want: exec.CommandContext(t.Context(), terraformBin, "-chdir=foobar/", "workspace", "new", "staging"),
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := w.Subcommand(t.Context(), test.subcommand)
+		if got.Path != test.want.Path {
+			t.Errorf("Subcommand path is %v, want %v", got.Path, test.want.Path)
+		}
+
+		if !slices.Equal(got.Args, test.want.Args) {
+			t.Errorf("Subcommand args is %v, want %v", got.Args, test.want.Args)
+		}
+	}
+}
+
+func TestTerraform_Workspace_Subcommand_tf13(t *testing.T) {
+	v, _ := semver.NewVersion("0.13.0")
+	// setup types
+	w := &Workspace{
+		Directory: "foobar/",
+		Name:      "staging",
+		Version:   v,
+	}
+
+	//nolint:gosec // ignore G204
+	want := exec.CommandContext(
+		t.Context(),
+		terraformBin,
+		"workspace",
+		"delete",
+		"staging",
+		"foobar/",
+	)
+
+	got := w.Subcommand(t.Context(), "delete")
+	if !slices.Equal(got.Args, want.Args) {
+		t.Errorf("Subcommand args is %v, want %v", got.Args, want.Args)
+	}
+}
+
+func TestTerraform_Workspace_Exec_Error(t *testing.T) {
+	v, _ := semver.NewVersion("1.0.0")
+	// setup types
+	w := &Workspace{
+		Command:   "list",
+		Directory: "foobar/",
+		Version:   v,
+	}
+
+	err := w.Exec(t.Context())
+	if err == nil {
+		t.Errorf("Exec should have returned err")
+	}
+}
+
+func TestTerraform_Workspace_Select_NoName(t *testing.T) {
+	// setup types
+	w := &Workspace{Directory: "foobar/"}
+
+	err := w.Select(t.Context())
+	if err != nil {
+		t.Errorf("Select returned err: %v", err)
+	}
+}
+
+func TestTerraform_Workspace_Validate(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		workspace *Workspace
+		want      error
+	}{
+		{
+			workspace: &Workspace{Command: "list"},
+			want:      nil,
+		},
+		{
+			workspace: &Workspace{Command: "new", Name: "staging"},
+			want:      nil,
+		},
+		{
+			workspace: &Workspace{Command: "delete"},
+			want:      ErrNoWorkspace,
+		},
+		{
+			workspace: &Workspace{Command: "foobar", Name: "staging"},
+			want:      ErrInvalidWorkspaceCommand,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		err := test.workspace.Validate()
+		if !errors.Is(err, test.want) {
+			t.Errorf("Validate returned err %v, want %v", err, test.want)
+		}
+	}
+}